@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// An Agent picks a move for playerColor. Agents may be asked to play several games at once, so must be safe for concurrent use.
+type Agent interface {
+	Name() string
+	SelectMove(ctx context.Context, board game.Board, playerColor game.Color) (game.Move, error)
+}
+
+var (
+	ErrNoLegalMoves = errors.New("There are no legal moves to choose from")
+)
+
+// Random plays any legal move.
+type Random struct {
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+func NewRandom(seed int64) *Random {
+	return &Random{rand: rand.New(rand.NewSource(seed))}
+}
+
+func (r *Random) Name() string {
+	return "random"
+}
+
+func (r *Random) SelectMove(ctx context.Context, board game.Board, playerColor game.Color) (game.Move, error) {
+	moves := board.LegalMoves(playerColor)
+	if len(moves) == 0 {
+		return game.Move{}, ErrNoLegalMoves
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return moves[r.rand.Intn(len(moves))], nil
+}
+
+// Named wraps an agent to report a different name, so that differently configured copies of an agent can be told apart.
+type Named struct {
+	Agent
+	name string
+}
+
+func NewNamed(name string, agent Agent) *Named {
+	return &Named{Agent: agent, name: name}
+}
+
+func (n *Named) Name() string {
+	return n.name
+}
@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/headblockhead/focus-ai/eval"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+func TestRandom(t *testing.T) {
+	r := NewRandom(1)
+	b := game.NewStartingBoard()
+	for i := 0; i < 100; i++ {
+		move, err := r.SelectMove(context.Background(), b, game.RED)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		copied := b
+		err = copied.Apply(move, game.RED)
+		if err != nil {
+			t.Errorf("Expected a legal move, got %v", err)
+		}
+	}
+	_, err := r.SelectMove(context.Background(), game.NewBoard(), game.RED)
+	if err != ErrNoLegalMoves {
+		t.Errorf("Expected ErrNoLegalMoves, got %v", err)
+	}
+}
+
+func TestNamed(t *testing.T) {
+	n := NewNamed("other", NewRandom(1))
+	if n.Name() != "other" {
+		t.Errorf("Expected other, got %s", n.Name())
+	}
+}
+
+func TestGreedyTakesReserves(t *testing.T) {
+	b := game.NewBoard()
+	// Moving the red piece onto the full stack knocks the red piece at the bottom into RED's reserves.
+	for i := 0; i < 5; i++ {
+		b.AddPiece(4, 3, game.Piece{Color: game.GREEN, Exists: true}, game.GREEN)
+	}
+	b.Tiles[4][3].Pieces[0] = game.Piece{Color: game.RED, Exists: true}
+	b.AddPiece(3, 3, game.Piece{Color: game.RED, Exists: true}, game.RED)
+	b.AddPiece(2, 2, game.Piece{Color: game.GREEN, Exists: true}, game.GREEN)
+	g := NewGreedy(eval.Weights{eval.RESERVES: 1})
+	move, err := g.SelectMove(context.Background(), b, game.RED)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	x, y := move.Destination()
+	if x != 4 || y != 3 {
+		t.Errorf("Expected a move to 4, 3, got %v", move)
+	}
+}
+
+func TestLimitsContext(t *testing.T) {
+	if !LimitsFrom(context.Background()).IsZero() {
+		t.Errorf("Expected no limits on a plain context")
+	}
+	limits := SearchLimits{Depth: 4, Nodes: 100}
+	if LimitsFrom(WithLimits(context.Background(), limits)) != limits {
+		t.Errorf("Expected %+v, got %+v", limits, LimitsFrom(WithLimits(context.Background(), limits)))
+	}
+}
+
+func TestTimeManager(t *testing.T) {
+	tm := DefaultTimeManager()
+	start := game.NewStartingBoard()
+	empty := game.NewBoard()
+	if PhaseOf(&start) != OPENING || PhaseOf(&empty) != ENDGAME {
+		t.Errorf("Expected OPENING and ENDGAME, got %v and %v", PhaseOf(&start), PhaseOf(&empty))
+	}
+
+	if budget := tm.Budget(SearchLimits{Depth: 3}, &start); budget != (Budget{}) {
+		t.Errorf("Expected no time limit, got %+v", budget)
+	}
+	if budget := tm.Budget(SearchLimits{MoveTime: time.Second, Infinite: true}, &start); budget != (Budget{}) {
+		t.Errorf("Expected an infinite search to have no time limit, got %+v", budget)
+	}
+	if budget := tm.Budget(SearchLimits{MoveTime: 100 * time.Millisecond}, &start); budget.Soft != 90*time.Millisecond || budget.Hard != 90*time.Millisecond {
+		t.Errorf("Expected 90ms after the overhead, got %+v", budget)
+	}
+
+	limits := SearchLimits{Clock: 30 * time.Second, Increment: time.Second}
+	opening := tm.Budget(limits, &start)
+	endgame := tm.Budget(limits, &empty)
+	if opening.Soft < time.Second || opening.Soft > 2*time.Second || opening.Hard != 3*opening.Soft {
+		t.Errorf("Expected about a thirtieth of the clock plus most of the increment, got %+v", opening)
+	}
+	if endgame.Soft <= opening.Soft {
+		t.Errorf("Expected more time per move in the endgame, got %v and %v", opening.Soft, endgame.Soft)
+	}
+	if endgame.Hard > 10*time.Second {
+		t.Errorf("Expected no more than a third of the clock, got %v", endgame.Hard)
+	}
+	if short := tm.Budget(SearchLimits{Clock: 5 * time.Millisecond}, &start); short.Hard != time.Millisecond {
+		t.Errorf("Expected a moment to find a move with almost no time left, got %+v", short)
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/headblockhead/focus-ai/agent
+
+require (
+	github.com/headblockhead/focus-ai/eval v0.0.0
+	github.com/headblockhead/focus-ai/game v0.0.0
+)
+
+replace github.com/headblockhead/focus-ai/game v0.0.0 => ../game
+
+replace github.com/headblockhead/focus-ai/eval v0.0.0 => ../eval
+
+go 1.20
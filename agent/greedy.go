@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"math"
+
+	"github.com/headblockhead/focus-ai/eval"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// Greedy plays the move that leads to the best evaluation, looking no further ahead.
+type Greedy struct {
+	Weights eval.Weights
+}
+
+func NewGreedy(weights eval.Weights) *Greedy {
+	return &Greedy{Weights: weights}
+}
+
+func (g *Greedy) Name() string {
+	return "greedy"
+}
+
+func (g *Greedy) SelectMove(ctx context.Context, board game.Board, playerColor game.Color) (game.Move, error) {
+	moves := board.LegalMoves(playerColor)
+	if len(moves) == 0 {
+		return game.Move{}, ErrNoLegalMoves
+	}
+	best := moves[0]
+	bestScore := math.Inf(-1)
+	for _, move := range moves {
+		after := board
+		err := after.Apply(move, playerColor)
+		if err != nil {
+			continue
+		}
+		// Leaving the opponent with nothing to do wins outright.
+		if !after.HasLegalMove(playerColor.Opponent()) {
+			return move, nil
+		}
+		score := g.Weights.Evaluate(&after, playerColor)
+		if score > bestScore {
+			best, bestScore = move, score
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// Info is progress reported by a search while it thinks.
+type Info struct {
+	Depth int
+	// Score is in whatever units the search uses, from the point of view of the player to move.
+	Score float64
+	Nodes uint64
+	Time  time.Duration
+	// PV is the principal variation, the line of play the search expects.
+	PV []game.Move
+}
+
+type infoKey struct{}
+
+// WithInfo attaches a function to a context for agents to report their progress to. It may be called from any goroutine.
+func WithInfo(ctx context.Context, report func(Info)) context.Context {
+	return context.WithValue(ctx, infoKey{}, report)
+}
+
+// ReportInfo passes info to the function attached to the context, if there is one.
+func ReportInfo(ctx context.Context, info Info) {
+	if report, ok := ctx.Value(infoKey{}).(func(Info)); ok {
+		report(info)
+	}
+}
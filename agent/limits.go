@@ -0,0 +1,149 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// SearchLimits say how long an agent may think about a move. Zero values are not limits.
+// With none set and Infinite unset, an agent uses its own defaults.
+type SearchLimits struct {
+	// MoveTime is a fixed time for this move.
+	MoveTime time.Duration
+	// Clock is the time the player has left for the rest of the game, and Increment is added to it after every move.
+	Clock     time.Duration
+	Increment time.Duration
+	Nodes     uint64
+	Depth     int
+	// Infinite searches until the context is cancelled, ignoring the other limits.
+	Infinite bool
+}
+
+// Timed reports whether the limits include a time limit.
+func (l SearchLimits) Timed() bool {
+	return !l.Infinite && (l.MoveTime > 0 || l.Clock > 0)
+}
+
+// IsZero reports whether no limits are set, so an agent should use its own defaults.
+func (l SearchLimits) IsZero() bool {
+	return l == SearchLimits{}
+}
+
+type limitsKey struct{}
+
+// WithLimits attaches limits to a context, so they reach the agent through SelectMove.
+// To stop a search early, such as an Infinite one, cancel the context.
+func WithLimits(ctx context.Context, limits SearchLimits) context.Context {
+	return context.WithValue(ctx, limitsKey{}, limits)
+}
+
+// LimitsFrom returns the limits attached to a context, or the zero SearchLimits if there are none.
+func LimitsFrom(ctx context.Context) SearchLimits {
+	limits, _ := ctx.Value(limitsKey{}).(SearchLimits)
+	return limits
+}
+
+type Phase int
+
+const (
+	OPENING Phase = iota
+	MIDDLEGAME
+	ENDGAME
+)
+
+func (p Phase) String() string {
+	switch p {
+	case OPENING:
+		return "OPENING"
+	case MIDDLEGAME:
+		return "MIDDLEGAME"
+	}
+	return "ENDGAME"
+}
+
+// PhaseOf judges how far through the game a board is by how many pieces are left, on the board and in reserve. A game starts with 36.
+func PhaseOf(b *game.Board) Phase {
+	pieces := b.ReservesR + b.ReservesG
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			pieces += b.Tiles[x][y].Height()
+		}
+	}
+	if pieces > 28 {
+		return OPENING
+	} else if pieces > 14 {
+		return MIDDLEGAME
+	}
+	return ENDGAME
+}
+
+// A Budget is how long to spend on a move. A search should try to finish within Soft, and must stop at Hard. Zero means no limit.
+type Budget struct {
+	Soft time.Duration
+	Hard time.Duration
+}
+
+// Context returns a context that is cancelled when the hard limit runs out.
+func (b Budget) Context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.Hard <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, b.Hard)
+}
+
+// TimeManager shares out a clock between the moves of a game.
+type TimeManager struct {
+	// Overhead is kept back from every move for the time it takes to play it.
+	Overhead time.Duration
+	// MovesLeft is roughly how many more moves a player will make from each phase of the game. Time is shared out evenly between them.
+	MovesLeft [3]int
+	// HardFactor is how many times the soft budget a move may take when a search needs to finish.
+	HardFactor float64
+}
+
+func DefaultTimeManager() TimeManager {
+	return TimeManager{
+		Overhead: 10 * time.Millisecond,
+		// Fewer moves are left to share the clock between as the game goes on, so each gets more time.
+		MovesLeft:  [3]int{30, 18, 12},
+		HardFactor: 3,
+	}
+}
+
+// Budget works out how long to spend on the next move from the board.
+func (tm TimeManager) Budget(limits SearchLimits, b *game.Board) (budget Budget) {
+	if !limits.Timed() {
+		return budget
+	}
+	if limits.Clock > 0 {
+		available := limits.Clock - tm.Overhead
+		budget.Soft = available/time.Duration(tm.MovesLeft[PhaseOf(b)]) + limits.Increment*3/4
+		budget.Hard = time.Duration(float64(budget.Soft) * tm.HardFactor)
+		// Never risk more than a third of what's left on one move.
+		if budget.Soft > available/3 {
+			budget.Soft = available / 3
+		}
+		if budget.Hard > available/3 {
+			budget.Hard = available / 3
+		}
+	}
+	if limits.MoveTime > 0 {
+		moveTime := limits.MoveTime - tm.Overhead
+		if budget.Hard == 0 || moveTime < budget.Hard {
+			budget.Hard = moveTime
+		}
+		if budget.Soft == 0 || moveTime < budget.Soft {
+			budget.Soft = moveTime
+		}
+	}
+	// Leave at least a moment, so that a search always has time to find a legal move.
+	if budget.Soft < time.Millisecond {
+		budget.Soft = time.Millisecond
+	}
+	if budget.Hard < time.Millisecond {
+		budget.Hard = time.Millisecond
+	}
+	return budget
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/book"
+	"github.com/headblockhead/focus-ai/eval"
+	"github.com/headblockhead/focus-ai/protocol"
+	"github.com/headblockhead/focus-ai/search"
+	"github.com/headblockhead/focus-ai/tablebase"
+)
+
+// newAgent builds an agent from a spec such as "random:seed=2" or "alphabeta:depth=4,weights=weights.json,book=book.bin".
+// The agent is named after the spec, so that differently configured agents can be told apart.
+// closeAgent should be called once the agent is finished with, to stop any engine it started.
+func newAgent(spec string) (a agent.Agent, closeAgent func(), err error) {
+	// Nothing needs closing unless the agent runs an engine.
+	cleanup := func() {}
+	defer func() {
+		if err != nil {
+			cleanup()
+		}
+	}()
+	name, options, err := parseAgentSpec(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch name {
+	case "random":
+		seed, err := options.intValue("seed", 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		a = agent.NewRandom(int64(seed))
+	case "greedy":
+		weights, err := loadWeights(options.stringValue("weights", ""))
+		if err != nil {
+			return nil, nil, err
+		}
+		a = agent.NewGreedy(weights)
+	case "alphabeta":
+		weights, err := loadWeights(options.stringValue("weights", ""))
+		if err != nil {
+			return nil, nil, err
+		}
+		depth, err := options.intValue("depth", 3)
+		if err != nil {
+			return nil, nil, err
+		}
+		table, err := newTable(options)
+		if err != nil {
+			return nil, nil, err
+		}
+		ab := search.NewAlphaBeta(weights, depth, table)
+		ab.Threads, err = options.intValue("threads", 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		ab.Deterministic, err = options.boolValue("deterministic", false)
+		if err != nil {
+			return nil, nil, err
+		}
+		ab.Tablebase, err = loadTablebase(options.stringValue("tablebase", ""))
+		if err != nil {
+			return nil, nil, err
+		}
+		a = ab
+	case "mcts":
+		weights, err := loadWeights(options.stringValue("weights", ""))
+		if err != nil {
+			return nil, nil, err
+		}
+		iterations, err := options.intValue("iterations", 2000)
+		if err != nil {
+			return nil, nil, err
+		}
+		m := search.NewMCTS(weights, iterations)
+		m.Threads, err = options.intValue("threads", 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		m.VirtualLoss, err = options.intValue("virtualloss", m.VirtualLoss)
+		if err != nil {
+			return nil, nil, err
+		}
+		m.Scale, err = options.floatValue("scale", m.Scale)
+		if err != nil {
+			return nil, nil, err
+		}
+		m.Exploration, err = options.floatValue("exploration", m.Exploration)
+		if err != nil {
+			return nil, nil, err
+		}
+		m.Deterministic, err = options.boolValue("deterministic", false)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch options.stringValue("parallel", "tree") {
+		case "tree":
+			m.Parallelism = search.TREE
+		case "root":
+			m.Parallelism = search.ROOT
+		default:
+			return nil, nil, fmt.Errorf("Agent option parallel in %q should be tree or root", spec)
+		}
+		if _, ok := options.values["hash"]; ok {
+			m.Table, err = newTable(options)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		m.Tablebase, err = loadTablebase(options.stringValue("tablebase", ""))
+		if err != nil {
+			return nil, nil, err
+		}
+		a = m
+	case "engine":
+		// Arguments for the engine program are separated by spaces.
+		command := strings.Fields(options.stringValue("command", ""))
+		if len(command) == 0 {
+			return nil, nil, fmt.Errorf("Agent option command in %q is required", spec)
+		}
+		// An engine should be ready almost at once, it only has to say hello.
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		client, err := protocol.Start(ctx, command[0], command[1:]...)
+		if err != nil {
+			return nil, nil, err
+		}
+		a = client
+		cleanup = func() {
+			client.Close()
+		}
+	default:
+		return nil, nil, fmt.Errorf("Unknown agent %q", name)
+	}
+	// Any agent can play from an opening book first.
+	if path := options.stringValue("book", ""); path != "" {
+		openings, err := book.Load(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		mode, err := book.ParseMode(options.stringValue("bookmode", "weighted"))
+		if err != nil {
+			return nil, nil, err
+		}
+		seed, err := options.intValue("bookseed", 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		a = book.NewAgent(openings, a, mode, int64(seed))
+	}
+	err = options.unused()
+	if err != nil {
+		return nil, nil, err
+	}
+	return agent.NewNamed(spec, a), cleanup, nil
+}
+
+// loadWeights loads evaluation weights from path, or returns the defaults if path is empty.
+func loadWeights(path string) (eval.Weights, error) {
+	if path == "" {
+		return eval.DefaultWeights(), nil
+	}
+	return eval.LoadWeights(path)
+}
+
+// loadTablebase loads a tablebase file, or returns nil if path is empty.
+func loadTablebase(path string) (*tablebase.Tablebase, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return tablebase.Load(path)
+}
+
+// newTable makes a transposition table sized by the hash option in megabytes, replacing entries as set by the replace option.
+func newTable(options agentOptions) (*search.Table, error) {
+	megabytes, err := options.intValue("hash", 16)
+	if err != nil {
+		return nil, err
+	}
+	replacement := search.DEPTH_PREFERRED
+	switch options.stringValue("replace", "depth") {
+	case "depth":
+	case "always":
+		replacement = search.ALWAYS_REPLACE
+	default:
+		return nil, fmt.Errorf("Agent option replace in %q should be depth or always", options.spec)
+	}
+	return search.NewTable(megabytes, replacement), nil
+}
+
+type agentOptions struct {
+	spec   string
+	values map[string]string
+	used   map[string]bool
+}
+
+func parseAgentSpec(spec string) (name string, options agentOptions, err error) {
+	name, rest, _ := strings.Cut(spec, ":")
+	options = agentOptions{spec: spec, values: map[string]string{}, used: map[string]bool{}}
+	if rest == "" {
+		return name, options, nil
+	}
+	for _, option := range strings.Split(rest, ",") {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok {
+			return name, options, fmt.Errorf("Agent option %q in %q should look like key=value", option, spec)
+		}
+		options.values[key] = value
+	}
+	return name, options, nil
+}
+
+func (o agentOptions) stringValue(key string, fallback string) string {
+	o.used[key] = true
+	value, ok := o.values[key]
+	if !ok {
+		return fallback
+	}
+	return value
+}
+
+func (o agentOptions) intValue(key string, fallback int) (int, error) {
+	o.used[key] = true
+	value, ok := o.values[key]
+	if !ok {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback, fmt.Errorf("Agent option %s in %q should be a whole number", key, o.spec)
+	}
+	return n, nil
+}
+
+func (o agentOptions) floatValue(key string, fallback float64) (float64, error) {
+	o.used[key] = true
+	value, ok := o.values[key]
+	if !ok {
+		return fallback, nil
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fallback, fmt.Errorf("Agent option %s in %q should be a number", key, o.spec)
+	}
+	return n, nil
+}
+
+func (o agentOptions) boolValue(key string, fallback bool) (bool, error) {
+	o.used[key] = true
+	value, ok := o.values[key]
+	if !ok {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback, fmt.Errorf("Agent option %s in %q should be true or false", key, o.spec)
+	}
+	return b, nil
+}
+
+func (o agentOptions) unused() error {
+	for key := range o.values {
+		if !o.used[key] {
+			return fmt.Errorf("Unknown agent option %s in %q", key, o.spec)
+		}
+	}
+	return nil
+}
+
+// agentList is a flag that can be given more than once.
+type agentList []string
+
+func (a *agentList) String() string {
+	return strings.Join(*a, " ")
+}
+
+func (a *agentList) Set(spec string) error {
+	*a = append(*a, spec)
+	return nil
+}
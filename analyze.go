@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// analyze has an agent search a position, printing its progress as it goes.
+func analyze(args []string) (err error) {
+	flags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	spec := flags.String("agent", "alphabeta", "The agent to search with")
+	position := flags.String("position", "start", "Position to search")
+	moves := flags.String("moves", "", "Moves to play from the position first, separated by spaces")
+	limits := agent.SearchLimits{MoveTime: 5 * time.Second}
+	limitFlags(flags, &limits)
+	infinite := flags.Bool("infinite", false, "Search until interrupted")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	board, turn, err := game.ParsePosition(*position)
+	if err != nil {
+		return err
+	}
+	for _, notation := range strings.Fields(*moves) {
+		move, err := game.ParseMove(notation)
+		if err != nil {
+			return err
+		}
+		if err = board.Apply(move, turn); err != nil {
+			return fmt.Errorf("%v: %w", move, err)
+		}
+		turn = turn.Opponent()
+	}
+	a, closeAgent, err := newAgent(*spec)
+	if err != nil {
+		return err
+	}
+	defer closeAgent()
+	limits.Infinite = *infinite
+
+	// Interrupting stops the search, and still shows the move it found.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx = agent.WithInfo(agent.WithLimits(ctx, limits), func(info agent.Info) {
+		pv := make([]string, len(info.PV))
+		for i, move := range info.PV {
+			pv[i] = move.String()
+		}
+		fmt.Printf("depth %-3d score %8.3f nodes %-10d time %-8v pv %s\n", info.Depth, info.Score, info.Nodes, info.Time.Round(time.Millisecond), strings.Join(pv, " "))
+	})
+	fmt.Println(game.FormatPosition(board, turn))
+	move, err := a.SelectMove(ctx, board, turn)
+	if errors.Is(err, agent.ErrNoLegalMoves) {
+		fmt.Printf("%v has no legal moves\n", turn)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Best move for %v: %v\n", turn, move)
+	return nil
+}
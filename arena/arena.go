@@ -0,0 +1,238 @@
+package arena
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+type Config struct {
+	// Games is the number of games to play. Colours swap every game, so each opening is played from both sides.
+	Games int
+	// MaxPlies is the number of moves after which a game is drawn.
+	MaxPlies int
+	// RandomPlies is the number of random moves played from the standard opening before the agents take over.
+	RandomPlies int
+	Seed        int64
+	// Records, if set, has every finished game written to it.
+	Records io.Writer
+	// Limits, if set, are given to the agents for every move. Clock is the time each player starts the game with, and a player who runs out loses.
+	Limits agent.SearchLimits
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Games:       100,
+		MaxPlies:    400,
+		RandomPlies: 4,
+		Seed:        1,
+	}
+}
+
+// An Opening is the position the agents start playing from, reached from Board by Moves.
+type Opening struct {
+	Board game.Board
+	Turn  game.Color
+	Moves []game.Move
+}
+
+func (o Opening) Position() (board game.Board, turn game.Color, err error) {
+	board, turn = o.Board, o.Turn
+	for _, move := range o.Moves {
+		err = board.Apply(move, turn)
+		if err != nil {
+			return board, turn, err
+		}
+		turn = turn.Opponent()
+	}
+	return board, turn, nil
+}
+
+// RandomOpenings plays plies random moves from NewStartingBoard for each opening.
+func RandomOpenings(count int, plies int, seed int64) []Opening {
+	random := rand.New(rand.NewSource(seed))
+	openings := make([]Opening, 0, count)
+	for len(openings) < count {
+		opening := Opening{Board: game.NewStartingBoard(), Turn: game.RED}
+		board, turn := opening.Board, opening.Turn
+		for i := 0; i < plies; i++ {
+			moves := board.LegalMoves(turn)
+			if len(moves) == 0 {
+				break
+			}
+			move := moves[random.Intn(len(moves))]
+			board.Apply(move, turn)
+			opening.Moves = append(opening.Moves, move)
+			turn = turn.Opponent()
+		}
+		// Don't hand the agents a game that is already over.
+		if !board.HasLegalMove(turn) {
+			continue
+		}
+		openings = append(openings, opening)
+	}
+	return openings
+}
+
+// Play plays a single game between red and green from the opening.
+// An agent that returns an illegal move loses; an agent that returns an error stops the game.
+func Play(ctx context.Context, red agent.Agent, green agent.Agent, opening Opening, maxPlies int) (record game.Record, err error) {
+	return PlayTimed(ctx, red, green, opening, maxPlies, agent.SearchLimits{})
+}
+
+// PlayTimed is Play with limits given to the agents for every move. If the limits have a Clock, each player's clock is kept and a player who runs out of time loses.
+func PlayTimed(ctx context.Context, red agent.Agent, green agent.Agent, opening Opening, maxPlies int, limits agent.SearchLimits) (record game.Record, err error) {
+	record = game.Record{
+		Red:   red.Name(),
+		Green: green.Name(),
+		Start: opening.Board,
+		Turn:  opening.Turn,
+		Moves: append([]game.Move{}, opening.Moves...),
+	}
+	board, turn, err := opening.Position()
+	if err != nil {
+		return record, err
+	}
+	spectator := spectatorFrom(ctx, record)
+	if spectator != nil {
+		defer func() { spectator.Finished(record) }()
+	}
+	clocks := map[game.Color]time.Duration{game.RED: limits.Clock, game.GREEN: limits.Clock}
+	for ply := 0; ; ply++ {
+		if !board.HasLegalMove(turn) {
+			record.Result = game.WinFor(turn.Opponent())
+			record.Reason = fmt.Sprintf("%v has no legal moves", turn)
+			return record, nil
+		}
+		if ply >= maxPlies {
+			record.Result = game.DRAW
+			record.Reason = "move limit reached"
+			return record, nil
+		}
+		player := red
+		if turn == game.GREEN {
+			player = green
+		}
+		moveLimits := limits
+		moveLimits.Clock = clocks[turn]
+		moveCtx := ctx
+		if !limits.IsZero() {
+			moveCtx = agent.WithLimits(ctx, moveLimits)
+		}
+		if spectator != nil {
+			moveCtx = agent.WithInfo(moveCtx, spectator.Info)
+		}
+		start := time.Now()
+		move, err := player.SelectMove(moveCtx, board, turn)
+		if err != nil {
+			return record, fmt.Errorf("%s: %w", player.Name(), err)
+		}
+		if limits.Clock > 0 {
+			clocks[turn] -= time.Since(start)
+			if clocks[turn] < 0 {
+				record.Result = game.WinFor(turn.Opponent())
+				record.Reason = fmt.Sprintf("%v ran out of time", turn)
+				return record, nil
+			}
+			clocks[turn] += limits.Increment
+		}
+		err = board.Apply(move, turn)
+		if err != nil {
+			record.Result = game.WinFor(turn.Opponent())
+			record.Reason = fmt.Sprintf("%v played an illegal move: %v", turn, err)
+			return record, nil
+		}
+		record.Moves = append(record.Moves, move)
+		turn = turn.Opponent()
+		if spectator != nil {
+			// Give the spectator its own moves, as the record's keep growing.
+			seen := record
+			seen.Moves = append([]game.Move{}, record.Moves...)
+			spectator.Moved(seen, board, turn)
+		}
+	}
+}
+
+// Result is the outcome of a match, from the point of view of the first agent.
+type Result struct {
+	Wins    int
+	Draws   int
+	Losses  int
+	Records []game.Record
+}
+
+func (r Result) Games() int {
+	return r.Wins + r.Draws + r.Losses
+}
+
+// Score counts a win as 1 and a draw as a half.
+func (r Result) Score() float64 {
+	if r.Games() == 0 {
+		return 0
+	}
+	return (float64(r.Wins) + float64(r.Draws)/2) / float64(r.Games())
+}
+
+// Interval is the confidence interval of the score, z standard deviations either side (1.96 for 95%).
+func (r Result) Interval(z float64) (low float64, high float64) {
+	n := float64(r.Games())
+	if n == 0 {
+		return 0, 1
+	}
+	score := r.Score()
+	variance := (float64(r.Wins)*math.Pow(1-score, 2) + float64(r.Draws)*math.Pow(0.5-score, 2) + float64(r.Losses)*math.Pow(score, 2)) / n
+	margin := z * math.Sqrt(variance/n)
+	return math.Max(0, score-margin), math.Min(1, score+margin)
+}
+
+// Passes reports whether the first agent scored at least threshold, e.g. 0.55 to promote a new model over the current best.
+func (r Result) Passes(threshold float64) bool {
+	return r.Games() > 0 && r.Score() >= threshold
+}
+
+func (r Result) String() string {
+	low, high := r.Interval(1.96)
+	return fmt.Sprintf("+%d =%d -%d, score %.1f%% (95%% CI %.1f%%-%.1f%%)", r.Wins, r.Draws, r.Losses, r.Score()*100, low*100, high*100)
+}
+
+func (r *Result) add(record game.Record, firstIsRed bool) {
+	switch {
+	case record.Result == game.DRAW:
+		r.Draws++
+	case (record.Result == game.RED_WON) == firstIsRed:
+		r.Wins++
+	default:
+		r.Losses++
+	}
+}
+
+// Match plays a against b, alternating colours every game.
+func Match(ctx context.Context, a agent.Agent, b agent.Agent, config Config) (result Result, err error) {
+	openings := RandomOpenings((config.Games+1)/2, config.RandomPlies, config.Seed)
+	for i := 0; i < config.Games; i++ {
+		red, green := a, b
+		firstIsRed := i%2 == 0
+		if !firstIsRed {
+			red, green = b, a
+		}
+		record, err := PlayTimed(ctx, red, green, openings[i/2], config.MaxPlies, config.Limits)
+		if err != nil {
+			return result, err
+		}
+		if config.Records != nil {
+			err = game.WriteRecord(config.Records, record)
+			if err != nil {
+				return result, err
+			}
+		}
+		result.add(record, firstIsRed)
+		result.Records = append(result.Records, record)
+	}
+	return result, nil
+}
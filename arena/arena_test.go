@@ -0,0 +1,201 @@
+package arena
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+type illegal struct{}
+
+func (illegal) Name() string {
+	return "illegal"
+}
+
+func (illegal) SelectMove(ctx context.Context, board game.Board, playerColor game.Color) (game.Move, error) {
+	return game.Move{X: 0, Y: 0, Pieces: 1, Directions: []game.Direction{game.UP}}, nil
+}
+
+// slow takes its time over every move, unless it is told how long it has.
+type slow struct{}
+
+func (slow) Name() string {
+	return "slow"
+}
+
+func (slow) SelectMove(ctx context.Context, board game.Board, playerColor game.Color) (game.Move, error) {
+	wait := 20 * time.Millisecond
+	if limits := agent.LimitsFrom(ctx); limits.Timed() {
+		wait = agent.DefaultTimeManager().Budget(limits, &board).Soft
+	}
+	time.Sleep(wait)
+	return board.LegalMoves(playerColor)[0], nil
+}
+
+func TestMatch(t *testing.T) {
+	var records bytes.Buffer
+	config := DefaultConfig()
+	config.Games = 10
+	config.Records = &records
+	result, err := Match(context.Background(), agent.NewRandom(1), agent.NewRandom(2), config)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if result.Games() != 10 {
+		t.Errorf("Expected 10 games, got %d", result.Games())
+	}
+	saved, err := game.ReadRecords(&records)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(saved) != 10 {
+		t.Errorf("Expected 10 saved records, got %d", len(saved))
+	}
+	for _, record := range saved {
+		if record.Result == game.UNDECIDED {
+			t.Errorf("Expected a finished game, got %v", record.Reason)
+		}
+		_, err := record.Positions()
+		if err != nil {
+			t.Errorf("Expected the record to replay, got %v", err)
+		}
+	}
+	if saved[0].Red != "random" || saved[1].Red != "random" {
+		t.Errorf("Expected agent names in records, got %s and %s", saved[0].Red, saved[1].Red)
+	}
+}
+
+func TestMatchIllegalMoveLoses(t *testing.T) {
+	config := DefaultConfig()
+	config.Games = 4
+	result, err := Match(context.Background(), agent.NewRandom(1), illegal{}, config)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if result.Wins != 4 {
+		t.Errorf("Expected 4 wins, got %v", result)
+	}
+	if !result.Passes(0.55) {
+		t.Errorf("Expected the match to pass the gate")
+	}
+}
+
+func TestResultInterval(t *testing.T) {
+	r := Result{Wins: 55, Draws: 10, Losses: 35}
+	if r.Score() != 0.6 {
+		t.Errorf("Expected 0.6, got %f", r.Score())
+	}
+	low, high := r.Interval(1.96)
+	if low >= 0.6 || high <= 0.6 || low < 0.45 || high > 0.75 {
+		t.Errorf("Expected a sensible interval around 0.6, got %f-%f", low, high)
+	}
+	low, high = Result{}.Interval(1.96)
+	if low != 0 || high != 1 {
+		t.Errorf("Expected 0-1 with no games, got %f-%f", low, high)
+	}
+	if (Result{}).Passes(0.5) {
+		t.Errorf("Expected an empty match not to pass")
+	}
+}
+
+func TestRandomOpenings(t *testing.T) {
+	openings := RandomOpenings(5, 6, 1)
+	if len(openings) != 5 {
+		t.Errorf("Expected 5 openings, got %d", len(openings))
+	}
+	for _, opening := range openings {
+		if len(opening.Moves) != 6 {
+			t.Errorf("Expected 6 moves, got %d", len(opening.Moves))
+		}
+		_, turn, err := opening.Position()
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if turn != game.RED {
+			t.Errorf("Expected RED to move after 6 plies, got %v", turn)
+		}
+	}
+}
+
+func TestPlayTimed(t *testing.T) {
+	opening := Opening{Board: game.NewStartingBoard(), Turn: game.RED}
+	record, err := PlayTimed(context.Background(), slow{}, agent.NewRandom(1), opening, 400, agent.SearchLimits{Clock: time.Second})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if record.Reason == "RED ran out of time" {
+		t.Errorf("Expected an agent that keeps to its budget not to run out of time")
+	}
+
+	record, err = Play(context.Background(), slow{}, agent.NewRandom(1), opening, 2)
+	if err != nil || record.Result != game.DRAW {
+		t.Errorf("Expected an untimed game to ignore how long moves take, got %v, %v", record.Reason, err)
+	}
+
+	// With no time to spare, slow still waits for its minimum budget.
+	record, _ = PlayTimed(context.Background(), slow{}, agent.NewRandom(1), opening, 400, agent.SearchLimits{Clock: time.Millisecond / 2})
+	if record.Result != game.GREEN_WON || record.Reason != "RED ran out of time" {
+		t.Errorf("Expected RED to lose on time, got %v: %s", record.Result, record.Reason)
+	}
+}
+
+// reporter plays the first legal move, reporting it as info.
+type reporter struct{}
+
+func (reporter) Name() string {
+	return "reporter"
+}
+
+func (reporter) SelectMove(ctx context.Context, board game.Board, playerColor game.Color) (game.Move, error) {
+	move := board.LegalMoves(playerColor)[0]
+	agent.ReportInfo(ctx, agent.Info{Depth: 1, PV: []game.Move{move}})
+	return move, nil
+}
+
+type watcher struct {
+	moves    int
+	infos    int
+	finished []game.Record
+}
+
+func (w *watcher) Moved(record game.Record, board game.Board, turn game.Color) {
+	w.moves++
+	if len(record.Moves) != w.moves {
+		panic("moves out of order")
+	}
+}
+
+func (w *watcher) Info(info agent.Info) {
+	w.infos++
+}
+
+func (w *watcher) Finished(record game.Record) {
+	w.finished = append(w.finished, record)
+}
+
+func TestSpectators(t *testing.T) {
+	w := &watcher{}
+	started := 0
+	ctx := WithSpectators(context.Background(), func(record game.Record) Spectator {
+		started++
+		return w
+	})
+	record, err := Play(ctx, reporter{}, agent.NewRandom(1), Opening{Board: game.NewStartingBoard(), Turn: game.RED}, 20)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if started != 1 || len(w.finished) != 1 || w.finished[0].Result != record.Result {
+		t.Errorf("Expected one game to be started and finished, got %d started and %d finished", started, len(w.finished))
+	}
+	if w.moves != len(record.Moves) {
+		t.Errorf("Expected %d moves, got %d", len(record.Moves), w.moves)
+	}
+	// Only red reports info.
+	if w.infos != (len(record.Moves)+1)/2 {
+		t.Errorf("Expected %d infos, got %d", (len(record.Moves)+1)/2, w.infos)
+	}
+}
@@ -0,0 +1,91 @@
+package arena
+
+import "math"
+
+type Rating struct {
+	Player string
+	Elo    float64
+	// Error is the 95% confidence margin either side of Elo.
+	Error float64
+	Games int
+	Score float64
+}
+
+// Rate fits Elo ratings to every game played using the Bradley-Terry model, counting draws as half a win each way.
+// Each player also gets prior virtual draws against an average (0 Elo) opponent, like BayesElo, so that players who win or lose everything still get a finite rating.
+// The ratings are centred on 0.
+func Rate(players []string, results [][]Result, prior float64) []Rating {
+	n := len(players)
+	points := make([]float64, n)
+	gamma := make([]float64, n)
+	for i := 0; i < n; i++ {
+		gamma[i] = 1
+		points[i] = prior / 2
+		for j := 0; j < n; j++ {
+			points[i] += float64(results[i][j].Wins) + float64(results[i][j].Draws)/2
+		}
+	}
+
+	// Minorization-maximization, see Hunter (2004) "MM algorithms for generalized Bradley-Terry models".
+	next := make([]float64, n)
+	for iteration := 0; iteration < 10000; iteration++ {
+		change := 0.0
+		for i := 0; i < n; i++ {
+			denominator := prior / (gamma[i] + 1)
+			for j := 0; j < n; j++ {
+				if games := results[i][j].Games(); games > 0 {
+					denominator += float64(games) / (gamma[i] + gamma[j])
+				}
+			}
+			next[i] = gamma[i]
+			if denominator > 0 {
+				next[i] = points[i] / denominator
+			}
+			// A player with no points at all would end up at 0, which log can't handle.
+			next[i] = math.Max(next[i], 1e-9)
+		}
+		logMean := 0.0
+		for i := 0; i < n; i++ {
+			logMean += math.Log(next[i]) / float64(n)
+		}
+		for i := 0; i < n; i++ {
+			next[i] /= math.Exp(logMean)
+			change = math.Max(change, math.Abs(math.Log(next[i])-math.Log(gamma[i])))
+			gamma[i] = next[i]
+		}
+		if change < 1e-9 {
+			break
+		}
+	}
+
+	eloPerNat := 400 / math.Ln10
+	ratings := make([]Rating, n)
+	for i := 0; i < n; i++ {
+		information := prior * expected(gamma[i], 1) * expected(1, gamma[i])
+		games := 0
+		score := 0.0
+		for j := 0; j < n; j++ {
+			result := results[i][j]
+			games += result.Games()
+			score += float64(result.Wins) + float64(result.Draws)/2
+			information += float64(result.Games()) * expected(gamma[i], gamma[j]) * expected(gamma[j], gamma[i])
+		}
+		ratings[i] = Rating{
+			Player: players[i],
+			Elo:    eloPerNat * math.Log(gamma[i]),
+			Error:  math.Inf(1),
+			Games:  games,
+		}
+		if information > 0 {
+			ratings[i].Error = 1.96 * eloPerNat / math.Sqrt(information)
+		}
+		if games > 0 {
+			ratings[i].Score = score / float64(games)
+		}
+	}
+	return ratings
+}
+
+func expected(gammaA float64, gammaB float64) float64 {
+	return gammaA / (gammaA + gammaB)
+}
@@ -0,0 +1,16 @@
+module github.com/headblockhead/focus-ai/arena
+
+require (
+	github.com/headblockhead/focus-ai/agent v0.0.0
+	github.com/headblockhead/focus-ai/game v0.0.0
+)
+
+require github.com/headblockhead/focus-ai/eval v0.0.0 // indirect
+
+replace github.com/headblockhead/focus-ai/game v0.0.0 => ../game
+
+replace github.com/headblockhead/focus-ai/eval v0.0.0 => ../eval
+
+replace github.com/headblockhead/focus-ai/agent v0.0.0 => ../agent
+
+go 1.20
@@ -0,0 +1,34 @@
+package arena
+
+import (
+	"context"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// A Spectator is told about a game while it is being played.
+type Spectator interface {
+	// Moved is called after every move with the game so far and the position it has reached.
+	Moved(record game.Record, board game.Board, turn game.Color)
+	// Info is called with the search info of the player to move.
+	Info(info agent.Info)
+	// Finished is called once with the finished game, which may not have a result if a player failed.
+	Finished(record game.Record)
+}
+
+type spectateKey struct{}
+
+// WithSpectators attaches a function to ctx that is called with the start of every game played with it, and returns the spectator for that game.
+func WithSpectators(ctx context.Context, spectate func(record game.Record) Spectator) context.Context {
+	return context.WithValue(ctx, spectateKey{}, spectate)
+}
+
+// spectatorFrom returns a spectator for the game, if ctx has any.
+func spectatorFrom(ctx context.Context, record game.Record) Spectator {
+	spectate, ok := ctx.Value(spectateKey{}).(func(record game.Record) Spectator)
+	if !ok {
+		return nil
+	}
+	return spectate(record)
+}
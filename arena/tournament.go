@@ -0,0 +1,288 @@
+package arena
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+type Format int
+
+const (
+	ROUND_ROBIN Format = iota
+	SWISS
+)
+
+func ParseFormat(name string) (Format, error) {
+	switch name {
+	case "roundrobin", "round-robin":
+		return ROUND_ROBIN, nil
+	case "swiss":
+		return SWISS, nil
+	}
+	return ROUND_ROBIN, fmt.Errorf("Unknown tournament format %q", name)
+}
+
+type TournamentConfig struct {
+	// Config.Games is the number of games each pairing plays per round.
+	Config
+	Format Format
+	Rounds int
+	// Concurrency is the number of games played at once.
+	Concurrency int
+	// Prior is the number of virtual draws against an average player added to every player's rating, which keeps the ratings of undefeated players finite.
+	Prior float64
+}
+
+func DefaultTournamentConfig() TournamentConfig {
+	config := TournamentConfig{
+		Config:      DefaultConfig(),
+		Format:      ROUND_ROBIN,
+		Rounds:      1,
+		Concurrency: 1,
+		Prior:       2,
+	}
+	config.Games = 2
+	return config
+}
+
+var (
+	ErrNotEnoughPlayers = errors.New("A tournament needs at least two players")
+	ErrDuplicatePlayer  = errors.New("Every player in a tournament needs a different name")
+)
+
+// Standings are the results of a tournament. Results[i][j] is player i's score against player j.
+type Standings struct {
+	Players []string
+	Results [][]Result
+	Ratings []Rating
+	Records []game.Record
+}
+
+type tournamentGame struct {
+	first      int
+	second     int
+	opening    Opening
+	firstIsRed bool
+}
+
+type tournamentOutcome struct {
+	game   tournamentGame
+	record game.Record
+	err    error
+}
+
+// RunTournament plays every round of the tournament, then rates the players.
+func RunTournament(ctx context.Context, players []agent.Agent, config TournamentConfig) (standings Standings, err error) {
+	if len(players) < 2 {
+		return standings, ErrNotEnoughPlayers
+	}
+	names := map[string]bool{}
+	for _, player := range players {
+		if names[player.Name()] {
+			return standings, ErrDuplicatePlayer
+		}
+		names[player.Name()] = true
+		standings.Players = append(standings.Players, player.Name())
+	}
+	standings.Results = make([][]Result, len(players))
+	for i := range standings.Results {
+		standings.Results[i] = make([]Result, len(players))
+	}
+
+	for round := 0; round < config.Rounds; round++ {
+		var pairings [][2]int
+		if config.Format == SWISS {
+			pairings = standings.swissPairings()
+		} else {
+			pairings = roundRobinPairings(len(players))
+		}
+		games := scheduleGames(pairings, config.Games, RandomOpenings(len(pairings)*((config.Games+1)/2), config.RandomPlies, config.Seed+int64(round)))
+		err = standings.play(ctx, players, games, config)
+		if err != nil {
+			return standings, err
+		}
+	}
+	standings.Ratings = Rate(standings.Players, standings.Results, config.Prior)
+	return standings, nil
+}
+
+func roundRobinPairings(players int) (pairings [][2]int) {
+	for i := 0; i < players; i++ {
+		for j := i + 1; j < players; j++ {
+			pairings = append(pairings, [2]int{i, j})
+		}
+	}
+	return pairings
+}
+
+// swissPairings pairs players with similar scores who haven't met yet, falling back to rematches when there is no one new left.
+// With an odd number of players the lowest placed unpaired player sits the round out.
+func (s *Standings) swissPairings() (pairings [][2]int) {
+	order := make([]int, len(s.Players))
+	points := make([]float64, len(s.Players))
+	for i := range order {
+		order[i] = i
+		for j := range s.Players {
+			points[i] += float64(s.Results[i][j].Wins) + float64(s.Results[i][j].Draws)/2
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return points[order[a]] > points[order[b]]
+	})
+	paired := make([]bool, len(s.Players))
+	for a, i := range order {
+		if paired[i] {
+			continue
+		}
+		opponent := -1
+		for _, j := range order[a+1:] {
+			if paired[j] {
+				continue
+			}
+			if opponent == -1 {
+				opponent = j
+			}
+			if s.Results[i][j].Games() == 0 {
+				opponent = j
+				break
+			}
+		}
+		if opponent == -1 {
+			continue
+		}
+		paired[i], paired[opponent] = true, true
+		pairings = append(pairings, [2]int{i, opponent})
+	}
+	return pairings
+}
+
+func scheduleGames(pairings [][2]int, gamesPerPairing int, openings []Opening) (games []tournamentGame) {
+	for p, pairing := range pairings {
+		for i := 0; i < gamesPerPairing; i++ {
+			games = append(games, tournamentGame{
+				first:      pairing[0],
+				second:     pairing[1],
+				opening:    openings[p*((gamesPerPairing+1)/2)+i/2],
+				firstIsRed: i%2 == 0,
+			})
+		}
+	}
+	return games
+}
+
+func (s *Standings) play(ctx context.Context, players []agent.Agent, games []tournamentGame, config TournamentConfig) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := config.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	queue := make(chan tournamentGame)
+	outcomes := make(chan tournamentOutcome)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range queue {
+				red, green := players[g.first], players[g.second]
+				if !g.firstIsRed {
+					red, green = green, red
+				}
+				record, err := PlayTimed(ctx, red, green, g.opening, config.MaxPlies, config.Limits)
+				outcomes <- tournamentOutcome{game: g, record: record, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(queue)
+		for _, g := range games {
+			select {
+			case queue <- g:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	for outcome := range outcomes {
+		if err != nil {
+			continue
+		}
+		if outcome.err != nil {
+			err = outcome.err
+			cancel()
+			continue
+		}
+		g := outcome.game
+		s.Results[g.first][g.second].add(outcome.record, g.firstIsRed)
+		s.Results[g.second][g.first].add(outcome.record, !g.firstIsRed)
+		s.Records = append(s.Records, outcome.record)
+		if config.Records != nil {
+			err = game.WriteRecord(config.Records, outcome.record)
+			if err != nil {
+				cancel()
+			}
+		}
+	}
+	return err
+}
+
+// WriteCrosstable writes the ratings, best first, followed by each player's points against every other player.
+func (s Standings) WriteCrosstable(w io.Writer) (err error) {
+	ratings := append([]Rating{}, s.Ratings...)
+	sort.SliceStable(ratings, func(a, b int) bool {
+		return ratings[a].Elo > ratings[b].Elo
+	})
+	index := map[string]int{}
+	for i, name := range s.Players {
+		index[name] = i
+	}
+	width := 6
+	for _, name := range s.Players {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	labelWidth := width + len(fmt.Sprint(len(ratings))) + 1
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-4s %-*s %7s %7s %6s %7s\n", "Rank", width, "Player", "Elo", "+/-", "Games", "Score")
+	for rank, rating := range ratings {
+		fmt.Fprintf(&b, "%-4d %-*s %7.1f %7.1f %6d %6.1f%%\n", rank+1, width, rating.Player, rating.Elo, rating.Error, rating.Games, rating.Score*100)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "%-*s", labelWidth, "")
+	for rank := range ratings {
+		fmt.Fprintf(&b, " %9d", rank+1)
+	}
+	b.WriteString("\n")
+	for rank, rating := range ratings {
+		fmt.Fprintf(&b, "%-*s", labelWidth, fmt.Sprintf("%d %s", rank+1, rating.Player))
+		for _, opponent := range ratings {
+			result := s.Results[index[rating.Player]][index[opponent.Player]]
+			if rating.Player == opponent.Player || result.Games() == 0 {
+				fmt.Fprintf(&b, " %9s", "-")
+				continue
+			}
+			points := float64(result.Wins) + float64(result.Draws)/2
+			fmt.Fprintf(&b, " %9s", fmt.Sprintf("%g/%d", points, result.Games()))
+		}
+		b.WriteString("\n")
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,123 @@
+package arena
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/headblockhead/focus-ai/agent"
+)
+
+func TestRate(t *testing.T) {
+	players := []string{"a", "b"}
+	results := [][]Result{
+		{{}, {Wins: 76, Losses: 24}},
+		{{Wins: 24, Losses: 76}, {}},
+	}
+	ratings := Rate(players, results, 0)
+	// A 76% score is 200 Elo.
+	difference := ratings[0].Elo - ratings[1].Elo
+	if math.Abs(difference-200) > 1 {
+		t.Errorf("Expected a difference of about 200, got %f", difference)
+	}
+	if math.Abs(ratings[0].Elo+ratings[1].Elo) > 1e-6 {
+		t.Errorf("Expected ratings centred on 0, got %v", ratings)
+	}
+	if ratings[0].Error <= 0 || math.IsInf(ratings[0].Error, 1) {
+		t.Errorf("Expected a finite error, got %f", ratings[0].Error)
+	}
+	if ratings[0].Games != 100 || ratings[0].Score != 0.76 {
+		t.Errorf("Expected 100 games at 76%%, got %v", ratings[0])
+	}
+
+	results = [][]Result{
+		{{}, {Wins: 10}},
+		{{Losses: 10}, {}},
+	}
+	ratings = Rate(players, results, 2)
+	if math.IsInf(ratings[0].Elo, 0) || ratings[0].Elo <= ratings[1].Elo {
+		t.Errorf("Expected a finite rating for an undefeated player, got %v", ratings)
+	}
+}
+
+func TestRunTournament(t *testing.T) {
+	players := []agent.Agent{
+		agent.NewNamed("a", agent.NewRandom(1)),
+		agent.NewNamed("b", agent.NewRandom(2)),
+		agent.NewNamed("c", agent.NewRandom(3)),
+		illegal{},
+	}
+	var records bytes.Buffer
+	config := DefaultTournamentConfig()
+	config.Concurrency = 4
+	config.Records = &records
+	standings, err := RunTournament(context.Background(), players, config)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	// 6 pairings of 2 games.
+	if len(standings.Records) != 12 {
+		t.Errorf("Expected 12 games, got %d", len(standings.Records))
+	}
+	if strings.Count(records.String(), "\n") != 12 {
+		t.Errorf("Expected 12 saved records")
+	}
+	for i := 0; i < 3; i++ {
+		if standings.Results[i][3].Wins != 2 || standings.Results[3][i].Losses != 2 {
+			t.Errorf("Expected every player to beat illegal, got %v", standings.Results[i][3])
+		}
+	}
+	if standings.Ratings[3].Elo >= standings.Ratings[0].Elo {
+		t.Errorf("Expected illegal to be rated lowest, got %v", standings.Ratings)
+	}
+
+	var crosstable bytes.Buffer
+	err = standings.WriteCrosstable(&crosstable)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(crosstable.String(), "4    illegal") {
+		t.Errorf("Expected illegal to come last, got\n%s", crosstable.String())
+	}
+}
+
+func TestRunTournamentSwiss(t *testing.T) {
+	players := []agent.Agent{
+		agent.NewNamed("a", agent.NewRandom(1)),
+		agent.NewNamed("b", agent.NewRandom(2)),
+		agent.NewNamed("c", agent.NewRandom(3)),
+		agent.NewNamed("d", agent.NewRandom(4)),
+		agent.NewNamed("e", agent.NewRandom(5)),
+	}
+	config := DefaultTournamentConfig()
+	config.Format = SWISS
+	config.Rounds = 3
+	standings, err := RunTournament(context.Background(), players, config)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	// 2 pairings of 2 games each round, one player sits out.
+	if len(standings.Records) != 12 {
+		t.Errorf("Expected 12 games, got %d", len(standings.Records))
+	}
+	for i := range players {
+		for j := range players {
+			if standings.Results[i][j].Games() > 2 {
+				t.Errorf("Expected no rematches, got %v between %d and %d", standings.Results[i][j], i, j)
+			}
+		}
+	}
+}
+
+func TestRunTournamentDuplicates(t *testing.T) {
+	_, err := RunTournament(context.Background(), []agent.Agent{agent.NewRandom(1), agent.NewRandom(2)}, DefaultTournamentConfig())
+	if err != ErrDuplicatePlayer {
+		t.Errorf("Expected ErrDuplicatePlayer, got %v", err)
+	}
+	_, err = RunTournament(context.Background(), []agent.Agent{agent.NewRandom(1)}, DefaultTournamentConfig())
+	if err != ErrNotEnoughPlayers {
+		t.Errorf("Expected ErrNotEnoughPlayers, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+type Mode int
+
+const (
+	// WEIGHTED picks book moves at random, in proportion to the points scored after them.
+	WEIGHTED Mode = iota
+	// BEST always picks the book move with the highest score.
+	BEST
+)
+
+var (
+	ErrUnknownMode = errors.New("Book modes are weighted or best")
+)
+
+func ParseMode(s string) (Mode, error) {
+	switch s {
+	case "weighted":
+		return WEIGHTED, nil
+	case "best":
+		return BEST, nil
+	}
+	return WEIGHTED, ErrUnknownMode
+}
+
+// Agent plays moves from the book while it has any, then leaves the rest of the game to Fallback.
+type Agent struct {
+	Book     *Book
+	Fallback agent.Agent
+	Mode     Mode
+	mutex    sync.Mutex
+	rand     *rand.Rand
+}
+
+func NewAgent(book *Book, fallback agent.Agent, mode Mode, seed int64) *Agent {
+	return &Agent{Book: book, Fallback: fallback, Mode: mode, rand: rand.New(rand.NewSource(seed))}
+}
+
+func (a *Agent) Name() string {
+	return "book+" + a.Fallback.Name()
+}
+
+func (a *Agent) SelectMove(ctx context.Context, board game.Board, playerColor game.Color) (game.Move, error) {
+	if move, ok := a.choose(a.Book.Moves(&board, playerColor)); ok {
+		return move, nil
+	}
+	return a.Fallback.SelectMove(ctx, board, playerColor)
+}
+
+func (a *Agent) choose(candidates []Candidate) (move game.Move, ok bool) {
+	if len(candidates) == 0 {
+		return move, false
+	}
+	if a.Mode == BEST {
+		best := candidates[0]
+		for _, c := range candidates[1:] {
+			if c.Entry.Score() > best.Entry.Score() || (c.Entry.Score() == best.Entry.Score() && c.Entry.Games > best.Entry.Games) {
+				best = c
+			}
+		}
+		return best.Move, true
+	}
+	total := 0
+	for _, c := range candidates {
+		total += int(c.Entry.Points)
+	}
+	// Every move in the book lost, so it's better to think.
+	if total == 0 {
+		return move, false
+	}
+	a.mutex.Lock()
+	pick := a.rand.Intn(total)
+	a.mutex.Unlock()
+	for _, c := range candidates {
+		pick -= int(c.Entry.Points)
+		if pick < 0 {
+			return c.Move, true
+		}
+	}
+	return move, false
+}
@@ -0,0 +1,173 @@
+package book
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// An Entry is how games went after reaching a position, from the point of view of the player who moved into it.
+// Keying on the position a move leads to, rather than the move, means different move orders that reach the same position share their results.
+type Entry struct {
+	Hash  uint64
+	Games uint32
+	// Points counts half points: 2 for a win and 1 for a draw.
+	Points uint32
+}
+
+// Score is the fraction of the points available that were won.
+func (e Entry) Score() float64 {
+	if e.Games == 0 {
+		return 0
+	}
+	return float64(e.Points) / float64(2*e.Games)
+}
+
+// Book is a set of entries sorted by hash.
+type Book struct {
+	entries []Entry
+}
+
+type BuildConfig struct {
+	// MaxPlies is how many moves into each game positions are added.
+	MaxPlies int
+	// MinGames leaves out positions reached in fewer games, whose results are mostly luck.
+	MinGames int
+}
+
+func DefaultBuildConfig() BuildConfig {
+	return BuildConfig{MaxPlies: 16, MinGames: 5}
+}
+
+var (
+	ErrNotBook = errors.New("The file is not an opening book")
+)
+
+// Build adds up the results of every decided game for each position in its first moves.
+func Build(records []game.Record, config BuildConfig) (b *Book, err error) {
+	totals := map[uint64]*Entry{}
+	for _, record := range records {
+		if record.Result == game.UNDECIDED {
+			continue
+		}
+		boards, err := record.Positions()
+		if err != nil {
+			return nil, err
+		}
+		for ply := 1; ply < len(boards) && ply <= config.MaxPlies; ply++ {
+			hash := boards[ply].Hash(record.TurnAt(ply))
+			entry, ok := totals[hash]
+			if !ok {
+				entry = &Entry{Hash: hash}
+				totals[hash] = entry
+			}
+			entry.Games++
+			mover := record.TurnAt(ply - 1)
+			if record.Result == game.WinFor(mover) {
+				entry.Points += 2
+			} else if record.Result == game.DRAW {
+				entry.Points += 1
+			}
+		}
+	}
+	b = &Book{}
+	for _, entry := range totals {
+		if int(entry.Games) >= config.MinGames {
+			b.entries = append(b.entries, *entry)
+		}
+	}
+	sort.Slice(b.entries, func(i, j int) bool { return b.entries[i].Hash < b.entries[j].Hash })
+	return b, nil
+}
+
+// Len returns the number of positions in the book.
+func (b *Book) Len() int {
+	return len(b.entries)
+}
+
+// Lookup finds the entry for a position.
+func (b *Book) Lookup(board *game.Board, turn game.Color) (e Entry, ok bool) {
+	hash := board.Hash(turn)
+	i := sort.Search(len(b.entries), func(i int) bool { return b.entries[i].Hash >= hash })
+	if i == len(b.entries) || b.entries[i].Hash != hash {
+		return e, false
+	}
+	return b.entries[i], true
+}
+
+// A Candidate is a legal move that leads to a position in the book.
+type Candidate struct {
+	Move  game.Move
+	Entry Entry
+}
+
+// Moves lists the moves from the board that lead to positions in the book.
+func (b *Book) Moves(board *game.Board, turn game.Color) (candidates []Candidate) {
+	for _, move := range board.LegalMoves(turn) {
+		child := *board
+		child.Apply(move, turn)
+		if entry, ok := b.Lookup(&child, turn.Opponent()); ok {
+			candidates = append(candidates, Candidate{Move: move, Entry: entry})
+		}
+	}
+	return candidates
+}
+
+// The file is a header, then every entry, all little endian.
+var magic = [8]byte{'F', 'O', 'C', 'U', 'S', 'B', 'K', '1'}
+
+type header struct {
+	Magic   [8]byte
+	Entries uint64
+}
+
+func (b *Book) WriteTo(w io.Writer) (n int64, err error) {
+	bw := bufio.NewWriter(w)
+	h := header{Magic: magic, Entries: uint64(len(b.entries))}
+	for _, data := range []any{h, b.entries} {
+		if err = binary.Write(bw, binary.LittleEndian, data); err != nil {
+			return n, err
+		}
+		n += int64(binary.Size(data))
+	}
+	return n, bw.Flush()
+}
+
+func Read(r io.Reader) (b *Book, err error) {
+	br := bufio.NewReader(r)
+	var h header
+	if err = binary.Read(br, binary.LittleEndian, &h); err != nil || h.Magic != magic {
+		return nil, ErrNotBook
+	}
+	b = &Book{entries: make([]Entry, h.Entries)}
+	if err = binary.Read(br, binary.LittleEndian, b.entries); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func Save(path string, b *Book) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err = b.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func Load(path string) (b *Book, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
@@ -0,0 +1,91 @@
+package book
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// records has RED opening with its first legal move 3 times and winning, then its second legal move once and losing.
+func records() (records []game.Record, good game.Move, bad game.Move) {
+	start := game.NewStartingBoard()
+	moves := start.LegalMoves(game.RED)
+	good, bad = moves[0], moves[1]
+	add := func(first game.Move, result game.Result) {
+		b := start
+		b.Apply(first, game.RED)
+		reply := b.LegalMoves(game.GREEN)[0]
+		records = append(records, game.Record{Start: start, Turn: game.RED, Moves: []game.Move{first, reply}, Result: result})
+	}
+	for i := 0; i < 3; i++ {
+		add(good, game.RED_WON)
+	}
+	add(bad, game.GREEN_WON)
+	records = append(records, game.Record{Start: start, Turn: game.RED, Moves: []game.Move{bad}, Result: game.UNDECIDED})
+	return records, good, bad
+}
+
+func TestBuild(t *testing.T) {
+	games, good, bad := records()
+	b, err := Build(games, BuildConfig{MaxPlies: 1, MinGames: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if b.Len() != 2 {
+		t.Errorf("Expected 2 positions, got %d", b.Len())
+	}
+	start := game.NewStartingBoard()
+	candidates := b.Moves(&start, game.RED)
+	if len(candidates) != 2 {
+		t.Fatalf("Expected 2 book moves, got %d", len(candidates))
+	}
+	for _, c := range candidates {
+		if c.Move.Equal(good) && (c.Entry.Games != 3 || c.Entry.Score() != 1) {
+			t.Errorf("Expected %v to have won 3 of 3, got %+v", good, c.Entry)
+		}
+		if c.Move.Equal(bad) && (c.Entry.Games != 1 || c.Entry.Score() != 0) {
+			t.Errorf("Expected %v to have lost its only decided game, got %+v", bad, c.Entry)
+		}
+	}
+
+	pruned, _ := Build(games, BuildConfig{MaxPlies: 2, MinGames: 2})
+	if pruned.Len() != 2 {
+		t.Errorf("Expected only the line played 3 times to be kept, got %d positions", pruned.Len())
+	}
+
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	read, err := Read(&buf)
+	if err != nil || read.Len() != b.Len() || read.entries[0] != b.entries[0] {
+		t.Errorf("Expected the book to read back the same, got %v", err)
+	}
+}
+
+func TestAgent(t *testing.T) {
+	games, good, _ := records()
+	b, _ := Build(games, BuildConfig{MaxPlies: 1, MinGames: 1})
+	start := game.NewStartingBoard()
+	for _, mode := range []Mode{BEST, WEIGHTED} {
+		a := NewAgent(b, agent.NewRandom(1), mode, 1)
+		for i := 0; i < 10; i++ {
+			move, err := a.SelectMove(context.Background(), start, game.RED)
+			if err != nil || !move.Equal(good) {
+				t.Errorf("Expected %v, the only book move that scored, got %v, %v", good, move, err)
+			}
+		}
+	}
+
+	// Off the book, the fallback plays.
+	a := NewAgent(b, agent.NewRandom(1), BEST, 1)
+	after := start
+	after.Apply(good, game.RED)
+	move, err := a.SelectMove(context.Background(), after, game.GREEN)
+	if err != nil || after.Apply(move, game.GREEN) != nil {
+		t.Errorf("Expected the fallback to play a legal move, got %v, %v", move, err)
+	}
+}
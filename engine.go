@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/headblockhead/focus-ai/protocol"
+)
+
+// engine runs an agent over the engine protocol on standard input and output, so other programs can play against it.
+func engine(args []string) (err error) {
+	flags := flag.NewFlagSet("engine", flag.ExitOnError)
+	spec := flags.String("agent", "alphabeta", "The agent to run, such as mcts:iterations=5000")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	a, closeAgent, err := newAgent(*spec)
+	if err != nil {
+		return err
+	}
+	defer closeAgent()
+	return protocol.Serve(context.Background(), a, os.Stdin, os.Stdout)
+}
@@ -0,0 +1,207 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+type Feature int
+
+// Every feature is the player's count minus their opponent's.
+const (
+	// Stacks with the player's piece on top.
+	CONTROLLED Feature = iota
+	// Pieces in stacks with the player's piece on top.
+	HEIGHT
+	// Stacks made only of the player's pieces, which are the only ones that can move.
+	MOVABLE
+	// The player's pieces on the board.
+	PIECES
+	// The player's pieces underneath an opponent's piece.
+	TRAPPED
+	RESERVES
+	// Legal moves, not counting placements from reserves.
+	MOBILITY
+	FEATURES
+)
+
+var featureNames = [FEATURES]string{
+	CONTROLLED: "controlled",
+	HEIGHT:     "height",
+	MOVABLE:    "movable",
+	PIECES:     "pieces",
+	TRAPPED:    "trapped",
+	RESERVES:   "reserves",
+	MOBILITY:   "mobility",
+}
+
+func (f Feature) String() string {
+	return featureNames[f]
+}
+
+type Vector [FEATURES]float64
+
+type Weights Vector
+
+func DefaultWeights() Weights {
+	return Weights{
+		CONTROLLED: 1,
+		HEIGHT:     0.5,
+		MOVABLE:    1,
+		PIECES:     0.5,
+		TRAPPED:    -0.5,
+		RESERVES:   1.5,
+		MOBILITY:   0.05,
+	}
+}
+
+// Features measures the board for playerColor.
+func Features(b *game.Board, playerColor game.Color) (v Vector) {
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			tile := &b.Tiles[x][y]
+			height := tile.Height()
+			if height == 0 {
+				continue
+			}
+			top := tile.Top().Color
+			sign := 1.0
+			if top != playerColor {
+				sign = -1
+			}
+			v[CONTROLLED] += sign
+			v[HEIGHT] += sign * float64(height)
+			movable := true
+			for i := 0; i < height; i++ {
+				pieceSign := 1.0
+				if tile.Pieces[i].Color != playerColor {
+					pieceSign = -1
+				}
+				v[PIECES] += pieceSign
+				if tile.Pieces[i].Color != top {
+					movable = false
+					v[TRAPPED] += pieceSign
+				}
+			}
+			if movable {
+				v[MOVABLE] += sign
+			}
+		}
+	}
+	v[RESERVES] = float64(*b.GetReserves(playerColor) - *b.GetReserves(playerColor.Opponent()))
+	v[MOBILITY] = float64(stackMoves(b, playerColor) - stackMoves(b, playerColor.Opponent()))
+	return v
+}
+
+func stackMoves(b *game.Board, playerColor game.Color) (count int) {
+	for _, move := range b.LegalMoves(playerColor) {
+		if !move.FromReserve {
+			count++
+		}
+	}
+	return count
+}
+
+func (w Weights) Score(v Vector) (score float64) {
+	for i := range v {
+		score += w[i] * v[i]
+	}
+	return score
+}
+
+// Evaluate scores the board for playerColor; higher is better.
+func (w Weights) Evaluate(b *game.Board, playerColor game.Color) float64 {
+	return w.Score(Features(b, playerColor))
+}
+
+// A Term is one feature's part of an evaluation.
+type Term struct {
+	Feature Feature
+	Value   float64
+	Weight  float64
+}
+
+func (t Term) Score() float64 {
+	return t.Value * t.Weight
+}
+
+// Breakdown lists what each feature added to the evaluation.
+func (w Weights) Breakdown(b *game.Board, playerColor game.Color) (terms []Term) {
+	v := Features(b, playerColor)
+	for i := Feature(0); i < FEATURES; i++ {
+		terms = append(terms, Term{Feature: i, Value: v[i], Weight: w[i]})
+	}
+	return terms
+}
+
+func WriteBreakdown(w io.Writer, terms []Term) (err error) {
+	var b strings.Builder
+	total := 0.0
+	fmt.Fprintf(&b, "%-12s %8s %8s %8s\n", "Feature", "Value", "Weight", "Score")
+	for _, term := range terms {
+		fmt.Fprintf(&b, "%-12s %8g %8.3f %8.3f\n", term.Feature, term.Value, term.Weight, term.Score())
+		total += term.Score()
+	}
+	fmt.Fprintf(&b, "%-12s %8s %8s %8.3f\n", "total", "", "", total)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// MarshalJSON writes the weights as an object keyed by feature name.
+func (w Weights) MarshalJSON() ([]byte, error) {
+	named := map[string]float64{}
+	for i := Feature(0); i < FEATURES; i++ {
+		named[i.String()] = w[i]
+	}
+	return json.Marshal(named)
+}
+
+// UnmarshalJSON reads weights keyed by feature name. Features that are left out keep their current weight.
+func (w *Weights) UnmarshalJSON(data []byte) (err error) {
+	var named map[string]float64
+	err = json.Unmarshal(data, &named)
+	if err != nil {
+		return err
+	}
+	for name, weight := range named {
+		feature, err := ParseFeature(name)
+		if err != nil {
+			return err
+		}
+		w[feature] = weight
+	}
+	return nil
+}
+
+func ParseFeature(name string) (Feature, error) {
+	for i := Feature(0); i < FEATURES; i++ {
+		if featureNames[i] == name {
+			return i, nil
+		}
+	}
+	return FEATURES, fmt.Errorf("Unknown feature %q", name)
+}
+
+// LoadWeights reads a weights file, starting from DefaultWeights for any features it doesn't mention.
+func LoadWeights(path string) (w Weights, err error) {
+	w = DefaultWeights()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return w, err
+	}
+	err = json.Unmarshal(data, &w)
+	return w, err
+}
+
+func SaveWeights(path string, w Weights) (err error) {
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,99 @@
+package eval
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+func TestFeaturesStartingBoard(t *testing.T) {
+	b := game.NewStartingBoard()
+	v := Features(&b, game.RED)
+	if v != (Vector{}) {
+		t.Errorf("Expected an even position, got %v", v)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	b := game.NewBoard()
+	b.AddPiece(3, 3, game.Piece{Color: game.GREEN, Exists: true}, game.GREEN)
+	b.AddPiece(3, 3, game.Piece{Color: game.RED, Exists: true}, game.RED)
+	b.AddPiece(4, 4, game.Piece{Color: game.RED, Exists: true}, game.RED)
+	b.AddPiece(4, 4, game.Piece{Color: game.RED, Exists: true}, game.RED)
+	b.SetReserves(game.GREEN, 2)
+	v := Features(&b, game.RED)
+	expected := Vector{
+		CONTROLLED: 2,
+		HEIGHT:     4,
+		MOVABLE:    1,
+		PIECES:     2,
+		TRAPPED:    -1,
+		RESERVES:   -2,
+		// 2 pieces at 4, 4 can reach 4 tiles with one and 8 with two, green can't move.
+		MOBILITY: 12,
+	}
+	if v != expected {
+		t.Errorf("Expected %v, got %v", expected, v)
+	}
+	opposite := Features(&b, game.GREEN)
+	for i := range v {
+		if opposite[i] != -v[i] {
+			t.Errorf("Expected GREEN's %v to be %g, got %g", Feature(i), -v[i], opposite[i])
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	b := game.NewBoard()
+	b.AddPiece(3, 3, game.Piece{Color: game.RED, Exists: true}, game.RED)
+	w := Weights{CONTROLLED: 2, RESERVES: 1}
+	b.SetReserves(game.GREEN, 3)
+	if w.Evaluate(&b, game.RED) != -1 {
+		t.Errorf("Expected -1, got %g", w.Evaluate(&b, game.RED))
+	}
+	total := 0.0
+	for _, term := range w.Breakdown(&b, game.RED) {
+		total += term.Score()
+	}
+	if total != -1 {
+		t.Errorf("Expected the breakdown to add up to -1, got %g", total)
+	}
+	var out bytes.Buffer
+	err := WriteBreakdown(&out, w.Breakdown(&b, game.RED))
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "reserves") || !strings.Contains(out.String(), "-1.000") {
+		t.Errorf("Expected a breakdown with reserves and the total, got\n%s", out.String())
+	}
+}
+
+func TestSaveLoadWeights(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weights.json")
+	w := DefaultWeights()
+	w[MOBILITY] = 0.25
+	err := SaveWeights(path, w)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	loaded, err := LoadWeights(path)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if loaded != w {
+		t.Errorf("Expected %v, got %v", w, loaded)
+	}
+
+	var partial Weights
+	err = partial.UnmarshalJSON([]byte(`{"reserves": 3}`))
+	if err != nil || partial[RESERVES] != 3 {
+		t.Errorf("Expected reserves of 3, got %v, %v", partial, err)
+	}
+	err = partial.UnmarshalJSON([]byte(`{"nonsense": 3}`))
+	if err == nil {
+		t.Errorf("Expected an error for an unknown feature")
+	}
+}
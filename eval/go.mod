@@ -0,0 +1,7 @@
+module github.com/headblockhead/focus-ai/eval
+
+require github.com/headblockhead/focus-ai/game v0.0.0
+
+replace github.com/headblockhead/focus-ai/game v0.0.0 => ../game
+
+go 1.20
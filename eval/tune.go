@@ -0,0 +1,190 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// A Sample is a position's features from the point of view of the player to move, labelled with how the game went for them: 1 for a win, 0.5 for a draw and 0 for a loss.
+type Sample struct {
+	Features Vector
+	Outcome  float64
+}
+
+type TuneConfig struct {
+	// SkipPlies leaves out the first moves of every game, which are often random.
+	SkipPlies    int
+	Iterations   int
+	LearningRate float64
+	// Every ValidateEvery-th game is held back to check the new weights on positions they weren't fitted to. 0 uses every game for fitting.
+	ValidateEvery int
+}
+
+func DefaultTuneConfig() TuneConfig {
+	return TuneConfig{
+		SkipPlies:     4,
+		Iterations:    2000,
+		LearningRate:  0.01,
+		ValidateEvery: 10,
+	}
+}
+
+var (
+	ErrNoSamples = errors.New("There are no positions from decided games to tune with")
+)
+
+// Samples labels every position in the records with the result of its game.
+func Samples(records []game.Record, skipPlies int) (samples []Sample, err error) {
+	for _, record := range records {
+		if record.Result == game.UNDECIDED {
+			continue
+		}
+		boards, err := record.Positions()
+		if err != nil {
+			return samples, err
+		}
+		for ply := skipPlies; ply < len(boards); ply++ {
+			turn := record.TurnAt(ply)
+			outcome := 0.5
+			if record.Result == game.WinFor(turn) {
+				outcome = 1
+			} else if record.Result == game.WinFor(turn.Opponent()) {
+				outcome = 0
+			}
+			samples = append(samples, Sample{Features: Features(&boards[ply], turn), Outcome: outcome})
+		}
+	}
+	return samples, nil
+}
+
+// Report describes how well some weights predict the outcome of a set of samples.
+type Report struct {
+	Samples int
+	// Loss is the mean log loss of the predicted win probability.
+	Loss float64
+	// Accuracy is how often the player with the better evaluation went on to win, leaving out drawn games.
+	Accuracy float64
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("%d positions, loss %.4f, accuracy %.1f%%", r.Samples, r.Loss, r.Accuracy*100)
+}
+
+// Measure reports how well the weights predict the samples. The win probability for an evaluation is sigmoid(scale * evaluation).
+func Measure(w Weights, scale float64, samples []Sample) (r Report) {
+	decisive, correct := 0, 0
+	for _, sample := range samples {
+		score := scale * w.Score(sample.Features)
+		r.Loss += logLoss(sigmoid(score), sample.Outcome)
+		if sample.Outcome != 0.5 {
+			decisive++
+			if (score > 0) == (sample.Outcome == 1) && score != 0 {
+				correct++
+			}
+		}
+	}
+	r.Samples = len(samples)
+	if r.Samples > 0 {
+		r.Loss /= float64(r.Samples)
+	}
+	if decisive > 0 {
+		r.Accuracy = float64(correct) / float64(decisive)
+	}
+	return r
+}
+
+// FitScale finds the scale that best turns the weights' evaluations into win probabilities, so that weights measured in different units can be compared fairly.
+func FitScale(w Weights, samples []Sample) float64 {
+	// The loss is convex in the scale, so a ternary search will do.
+	low, high := 0.0, 10.0
+	for i := 0; i < 100; i++ {
+		a := low + (high-low)/3
+		b := high - (high-low)/3
+		if Measure(w, a, samples).Loss < Measure(w, b, samples).Loss {
+			high = b
+		} else {
+			low = a
+		}
+	}
+	return (low + high) / 2
+}
+
+// Tune fits the weights to the samples by gradient descent on the log loss, keeping the scale the starting weights were measured with, so the new weights are in the same units.
+func Tune(w Weights, scale float64, samples []Sample, config TuneConfig) Weights {
+	if len(samples) == 0 {
+		return w
+	}
+	// Adam, which copes with features on very different scales far better than plain gradient descent.
+	var m, v Vector
+	beta1, beta2 := 0.9, 0.999
+	for iteration := 1; iteration <= config.Iterations; iteration++ {
+		var gradient Vector
+		for _, sample := range samples {
+			miss := sigmoid(scale*w.Score(sample.Features)) - sample.Outcome
+			for i := range gradient {
+				gradient[i] += miss * scale * sample.Features[i]
+			}
+		}
+		for i := range w {
+			g := gradient[i] / float64(len(samples))
+			m[i] = beta1*m[i] + (1-beta1)*g
+			v[i] = beta2*v[i] + (1-beta2)*g*g
+			mHat := m[i] / (1 - math.Pow(beta1, float64(iteration)))
+			vHat := v[i] / (1 - math.Pow(beta2, float64(iteration)))
+			w[i] -= config.LearningRate * mHat / (math.Sqrt(vHat) + 1e-8)
+		}
+	}
+	return w
+}
+
+// TuneRecords tunes the weights against a set of game records and writes a report comparing the starting and tuned weights.
+func TuneRecords(w Weights, records []game.Record, config TuneConfig, out io.Writer) (tuned Weights, err error) {
+	var training, validation []game.Record
+	for i, record := range records {
+		if config.ValidateEvery > 0 && i%config.ValidateEvery == config.ValidateEvery-1 {
+			validation = append(validation, record)
+		} else {
+			training = append(training, record)
+		}
+	}
+	trainingSamples, err := Samples(training, config.SkipPlies)
+	if err != nil {
+		return w, err
+	}
+	validationSamples, err := Samples(validation, config.SkipPlies)
+	if err != nil {
+		return w, err
+	}
+	if len(trainingSamples) == 0 {
+		return w, ErrNoSamples
+	}
+
+	scale := FitScale(w, trainingSamples)
+	tuned = Tune(w, scale, trainingSamples, config)
+
+	fmt.Fprintf(out, "Fitted to %d games, scale %.4f\n", len(training), scale)
+	fmt.Fprintf(out, "Training before:   %v\n", Measure(w, scale, trainingSamples))
+	fmt.Fprintf(out, "Training after:    %v\n", Measure(tuned, scale, trainingSamples))
+	if len(validationSamples) > 0 {
+		fmt.Fprintf(out, "Validation before: %v\n", Measure(w, scale, validationSamples))
+		fmt.Fprintf(out, "Validation after:  %v\n", Measure(tuned, scale, validationSamples))
+	}
+	fmt.Fprintf(out, "\n%-12s %8s %8s\n", "Feature", "Before", "After")
+	for i := Feature(0); i < FEATURES; i++ {
+		fmt.Fprintf(out, "%-12s %8.3f %8.3f\n", i, w[i], tuned[i])
+	}
+	return tuned, nil
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+func logLoss(predicted float64, outcome float64) float64 {
+	predicted = math.Min(math.Max(predicted, 1e-12), 1-1e-12)
+	return -(outcome*math.Log(predicted) + (1-outcome)*math.Log(1-predicted))
+}
@@ -0,0 +1,113 @@
+package eval
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+func TestTune(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	var samples []Sample
+	for i := 0; i < 500; i++ {
+		var v Vector
+		v[RESERVES] = float64(random.Intn(11) - 5)
+		v[MOBILITY] = float64(random.Intn(41) - 20)
+		outcome := 0.0
+		if v[RESERVES]+random.NormFloat64() > 0 {
+			outcome = 1
+		}
+		samples = append(samples, Sample{Features: v, Outcome: outcome})
+	}
+	config := DefaultTuneConfig()
+	tuned := Tune(Weights{}, 1, samples, config)
+	if tuned[RESERVES] <= 0.5 {
+		t.Errorf("Expected a large positive weight for reserves, got %g", tuned[RESERVES])
+	}
+	if tuned[MOBILITY] > 0.1 || tuned[MOBILITY] < -0.1 {
+		t.Errorf("Expected a small weight for mobility, got %g", tuned[MOBILITY])
+	}
+	before := Measure(Weights{}, 1, samples)
+	after := Measure(tuned, 1, samples)
+	if after.Loss >= before.Loss {
+		t.Errorf("Expected the loss to go down, got %v then %v", before, after)
+	}
+	if after.Accuracy < 0.75 {
+		t.Errorf("Expected an accuracy of at least 75%%, got %v", after)
+	}
+}
+
+func TestFitScale(t *testing.T) {
+	samples := []Sample{
+		{Features: Vector{RESERVES: 1}, Outcome: 1},
+		{Features: Vector{RESERVES: 1}, Outcome: 1},
+		{Features: Vector{RESERVES: 1}, Outcome: 1},
+		{Features: Vector{RESERVES: 1}, Outcome: 0},
+	}
+	// sigmoid(ln 3) = 0.75.
+	scale := FitScale(Weights{RESERVES: 1}, samples)
+	if scale < 1.09 || scale > 1.11 {
+		t.Errorf("Expected a scale of ln 3, got %g", scale)
+	}
+}
+
+func randomRecord(random *rand.Rand) game.Record {
+	record := game.Record{Start: game.NewStartingBoard(), Turn: game.RED, Result: game.DRAW}
+	board, turn := record.Start, record.Turn
+	for ply := 0; ply < 200; ply++ {
+		moves := board.LegalMoves(turn)
+		if len(moves) == 0 {
+			record.Result = game.WinFor(turn.Opponent())
+			break
+		}
+		move := moves[random.Intn(len(moves))]
+		board.Apply(move, turn)
+		record.Moves = append(record.Moves, move)
+		turn = turn.Opponent()
+	}
+	return record
+}
+
+func TestSamples(t *testing.T) {
+	record := randomRecord(rand.New(rand.NewSource(1)))
+	samples, err := Samples([]game.Record{record}, 4)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(samples) != len(record.Moves)+1-4 {
+		t.Errorf("Expected %d samples, got %d", len(record.Moves)+1-4, len(samples))
+	}
+	last := samples[len(samples)-1]
+	// The player left to move in the final position has lost.
+	if record.Result != game.DRAW && last.Outcome != 0 {
+		t.Errorf("Expected the last position to be lost, got %g", last.Outcome)
+	}
+	if samples[0].Outcome+samples[1].Outcome != 1 {
+		t.Errorf("Expected outcomes to alternate, got %g and %g", samples[0].Outcome, samples[1].Outcome)
+	}
+}
+
+func TestTuneRecords(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	var records []game.Record
+	for i := 0; i < 20; i++ {
+		records = append(records, randomRecord(random))
+	}
+	config := DefaultTuneConfig()
+	config.Iterations = 50
+	var report bytes.Buffer
+	_, err := TuneRecords(DefaultWeights(), records, config, &report)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(report.String(), "Validation after") || !strings.Contains(report.String(), "reserves") {
+		t.Errorf("Expected a report with validation and weights, got\n%s", report.String())
+	}
+	_, err = TuneRecords(DefaultWeights(), nil, config, &report)
+	if err != ErrNoSamples {
+		t.Errorf("Expected ErrNoSamples, got %v", err)
+	}
+}
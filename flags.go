@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/headblockhead/focus-ai/agent"
+)
+
+// configEnv names a config file to use when -config isn't given.
+const configEnv = "FOCUS_AI_CONFIG"
+
+// parseFlags parses a command's arguments, then fills in any flags that weren't given from the config file.
+//
+// The config file is JSON. Values at the top level are used by every command with a flag of that name, and values in an object named after a command only by that command, overriding the top level:
+//
+//	{"movetime": "200ms", "tournament": {"games": 10, "agent": ["random", "alphabeta:depth=2"]}}
+//
+// A list gives a flag more than once.
+func parseFlags(flags *flag.FlagSet, args []string) (err error) {
+	config := flags.String("config", os.Getenv(configEnv), "JSON file of flag values to use when they aren't given, also read from $"+configEnv)
+	if err = flags.Parse(args); err != nil {
+		return err
+	}
+	if *config == "" {
+		return nil
+	}
+	data, err := os.ReadFile(*config)
+	if err != nil {
+		return err
+	}
+	// Numbers are kept as they were written, so large ones aren't turned into floats.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var values map[string]any
+	if err = decoder.Decode(&values); err != nil {
+		return fmt.Errorf("%s: %w", *config, err)
+	}
+	command, _ := values[flags.Name()].(map[string]any)
+	given := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	var setErr error
+	flags.VisitAll(func(f *flag.Flag) {
+		if given[f.Name] || setErr != nil {
+			return
+		}
+		value, ok := command[f.Name]
+		if !ok {
+			value, ok = values[f.Name]
+		}
+		if !ok {
+			return
+		}
+		if setErr = setFlag(flags, f.Name, value); setErr != nil {
+			setErr = fmt.Errorf("%s: %s: %w", *config, f.Name, setErr)
+		}
+	})
+	return setErr
+}
+
+func setFlag(flags *flag.FlagSet, name string, value any) (err error) {
+	switch value := value.(type) {
+	case []any:
+		for _, v := range value {
+			if err = setFlag(flags, name, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]any:
+		return fmt.Errorf("Expected a value, got an object")
+	}
+	return flags.Set(name, fmt.Sprint(value))
+}
+
+// limitFlags adds the flags for the limits agents search within.
+func limitFlags(flags *flag.FlagSet, limits *agent.SearchLimits) {
+	flags.DurationVar(&limits.MoveTime, "movetime", limits.MoveTime, "Time for each move, such as 100ms")
+	flags.DurationVar(&limits.Clock, "clock", limits.Clock, "Time each player has for the whole game, running out loses")
+	flags.DurationVar(&limits.Increment, "increment", limits.Increment, "Time added to a player's clock after each move")
+	flags.Uint64Var(&limits.Nodes, "nodes", limits.Nodes, "Nodes searched for each move")
+	flags.IntVar(&limits.Depth, "depth", limits.Depth, "Depth searched for each move")
+}
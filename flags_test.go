@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+)
+
+// writeConfig writes a config file for a test, returning its path.
+func writeConfig(t *testing.T, config string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return path
+}
+
+// tournamentFlags are like the tournament command's, with a list of agents, games and limits.
+func tournamentFlags() (flags *flag.FlagSet, agents *agentList, games *int, limits *agent.SearchLimits) {
+	flags = flag.NewFlagSet("tournament", flag.ContinueOnError)
+	agents = &agentList{}
+	flags.Var(agents, "agent", "")
+	games = flags.Int("games", 2, "")
+	limits = &agent.SearchLimits{}
+	limitFlags(flags, limits)
+	return flags, agents, games, limits
+}
+
+func TestParseFlagsConfig(t *testing.T) {
+	t.Setenv(configEnv, "")
+	path := writeConfig(t, `{"movetime": "200ms", "depth": 3, "games": 4, "tournament": {"games": 10, "agent": ["random", "alphabeta:depth=2"]}, "selfplay": {"depth": 5}}`)
+	flags, agents, games, limits := tournamentFlags()
+	if err := parseFlags(flags, []string{"-config", path, "-movetime", "1s"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if limits.MoveTime != time.Second {
+		t.Errorf("Expected the command line's movetime of 1s to be kept, got %v", limits.MoveTime)
+	}
+	if limits.Depth != 3 {
+		t.Errorf("Expected the top level depth of 3, not another command's, got %d", limits.Depth)
+	}
+	if *games != 10 {
+		t.Errorf("Expected the tournament's games of 10 to override the top level, got %d", *games)
+	}
+	if len(*agents) != 2 || (*agents)[0] != "random" || (*agents)[1] != "alphabeta:depth=2" {
+		t.Errorf("Expected the list to give -agent twice, got %q", *agents)
+	}
+}
+
+func TestParseFlagsConfigEnv(t *testing.T) {
+	t.Setenv(configEnv, writeConfig(t, `{"games": 7}`))
+	flags, _, games, _ := tournamentFlags()
+	if err := parseFlags(flags, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *games != 7 {
+		t.Errorf("Expected the config from $%s to give games of 7, got %d", configEnv, *games)
+	}
+}
+
+func TestParseFlagsConfigInvalid(t *testing.T) {
+	t.Setenv(configEnv, "")
+	for _, config := range []string{
+		`{"games": {"count": 3}}`,
+		`{"tournament": {"games": {"count": 3}}}`,
+		`{"games": "many"}`,
+		`{"games": 3`,
+	} {
+		flags, _, _, _ := tournamentFlags()
+		if err := parseFlags(flags, []string{"-config", writeConfig(t, config)}); err == nil {
+			t.Errorf("Expected an error for %s", config)
+		}
+	}
+	flags, _, _, _ := tournamentFlags()
+	if err := parseFlags(flags, []string{"-config", filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Errorf("Expected an error for a missing config file")
+	}
+}
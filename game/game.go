@@ -62,6 +62,21 @@ func NewBoard() Board {
 	return b
 }
 
+// NewStartingBoard returns a board with the standard opening layout: the middle 6x6 tiles filled with alternating pairs of single pieces.
+func NewStartingBoard() Board {
+	b := NewBoard()
+	for i := 1; i < 7; i++ {
+		for j := 1; j < 7; j++ {
+			color := RED
+			if ((i-1)/2+(j-1))%2 == 1 {
+				color = GREEN
+			}
+			b.Tiles[i][j].Pieces[0] = Piece{Color: color, Exists: true}
+		}
+	}
+	return b
+}
+
 var (
 	ErrTileOutOfBounds = errors.New("Tile out of bounds")
 )
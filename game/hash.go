@@ -0,0 +1,53 @@
+package game
+
+// Zobrist keys, made from a fixed seed so that hashes are the same between runs and can be saved to disk.
+var (
+	pieceKeys   [8][8][5][2]uint64
+	reserveKeys [2][64]uint64
+	turnKey     uint64
+)
+
+func init() {
+	seed := uint64(0x466f637573414921)
+	next := func() uint64 {
+		// splitmix64
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			for k := 0; k < 5; k++ {
+				pieceKeys[x][y][k][RED] = next()
+				pieceKeys[x][y][k][GREEN] = next()
+			}
+		}
+	}
+	for i := 0; i < 64; i++ {
+		reserveKeys[RED][i] = next()
+		reserveKeys[GREEN][i] = next()
+	}
+	turnKey = next()
+}
+
+// Hash identifies the position with turn to move. Different positions can share a hash, but it is very unlikely.
+func (b *Board) Hash(turn Color) (hash uint64) {
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			for k := 0; k < 5; k++ {
+				piece := b.Tiles[x][y].Pieces[k]
+				if piece.Exists {
+					hash ^= pieceKeys[x][y][k][piece.Color]
+				}
+			}
+		}
+	}
+	hash ^= reserveKeys[RED][b.ReservesR&63]
+	hash ^= reserveKeys[GREEN][b.ReservesG&63]
+	if turn == GREEN {
+		hash ^= turnKey
+	}
+	return hash
+}
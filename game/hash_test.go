@@ -0,0 +1,33 @@
+package game
+
+import "testing"
+
+func TestHash(t *testing.T) {
+	b := NewStartingBoard()
+	if b.Hash(RED) == b.Hash(GREEN) {
+		t.Errorf("Expected the turn to change the hash")
+	}
+	copied := b
+	if copied.Hash(RED) != b.Hash(RED) {
+		t.Errorf("Expected equal boards to have equal hashes")
+	}
+	copied.AddToReserves(RED, 1)
+	if copied.Hash(RED) == b.Hash(RED) {
+		t.Errorf("Expected reserves to change the hash")
+	}
+
+	// The same position reached in a different order.
+	first, second := NewStartingBoard(), NewStartingBoard()
+	first.Apply(Move{X: 1, Y: 1, Pieces: 1, Directions: []Direction{RIGHT}}, RED)
+	first.Apply(Move{X: 3, Y: 1, Pieces: 1, Directions: []Direction{RIGHT}}, GREEN)
+	first.Apply(Move{X: 1, Y: 3, Pieces: 1, Directions: []Direction{RIGHT}}, RED)
+	second.Apply(Move{X: 1, Y: 3, Pieces: 1, Directions: []Direction{RIGHT}}, RED)
+	second.Apply(Move{X: 3, Y: 1, Pieces: 1, Directions: []Direction{RIGHT}}, GREEN)
+	second.Apply(Move{X: 1, Y: 1, Pieces: 1, Directions: []Direction{RIGHT}}, RED)
+	if first.Hash(GREEN) != second.Hash(GREEN) {
+		t.Errorf("Expected transposed positions to have equal hashes")
+	}
+	if first.Hash(GREEN) == b.Hash(GREEN) {
+		t.Errorf("Expected moves to change the hash")
+	}
+}
@@ -0,0 +1,213 @@
+package game
+
+import "errors"
+
+// A Move is either a stack move from X, Y or, if FromReserve is set, a placement of a reserve piece onto X, Y.
+type Move struct {
+	X           int
+	Y           int
+	Pieces      int
+	Directions  []Direction
+	FromReserve bool
+}
+
+func (c Color) Opponent() Color {
+	if c == RED {
+		return GREEN
+	}
+	return RED
+}
+
+func (c Color) String() string {
+	if c == RED {
+		return "RED"
+	}
+	return "GREEN"
+}
+
+func (t *Tile) Useable() bool {
+	return t.useable
+}
+
+func (t *Tile) Height() int {
+	height := 0
+	for i := 0; i < len(t.Pieces); i++ {
+		if t.Pieces[i].Exists {
+			height++
+		}
+	}
+	return height
+}
+
+// Top returns the highest piece on the tile, which does not exist if the tile is empty.
+func (t *Tile) Top() Piece {
+	for i := len(t.Pieces) - 1; i >= 0; i-- {
+		if t.Pieces[i].Exists {
+			return t.Pieces[i]
+		}
+	}
+	return Piece{}
+}
+
+// Destination returns the tile the move ends on.
+func (m Move) Destination() (x int, y int) {
+	x, y = m.X, m.Y
+	if m.FromReserve {
+		return x, y
+	}
+	for _, direction := range m.Directions {
+		switch direction {
+		case UP:
+			y -= 1
+		case DOWN:
+			y += 1
+		case LEFT:
+			x -= 1
+		case RIGHT:
+			x += 1
+		}
+	}
+	return x, y
+}
+
+// Equal reports whether two moves have the same effect, even if their directions are in a different order.
+func (m Move) Equal(other Move) bool {
+	if m.FromReserve != other.FromReserve || m.X != other.X || m.Y != other.Y {
+		return false
+	}
+	if m.FromReserve {
+		return true
+	}
+	x, y := m.Destination()
+	otherX, otherY := other.Destination()
+	return m.Pieces == other.Pieces && x == otherX && y == otherY
+}
+
+var (
+	ErrNoReserves = errors.New("You have no pieces in reserve")
+)
+
+// Apply plays a move for playerColor, using AddFromReserves or Move depending on the kind of move.
+func (b *Board) Apply(move Move, playerColor Color) (err error) {
+	if !move.FromReserve {
+		return b.Move(move.X, move.Y, move.Pieces, move.Directions, playerColor)
+	}
+	tile, err := b.GetTile(move.X, move.Y)
+	if err != nil {
+		return err
+	}
+	if !tile.useable {
+		return ErrTileDestinationUnusable
+	}
+	if *b.GetReserves(playerColor) <= 0 {
+		return ErrNoReserves
+	}
+	return b.AddFromReserves(playerColor, move.X, move.Y)
+}
+
+// LegalMoves lists every distinct move playerColor can make.
+// Moves that would leave the stack where it started are not included.
+func (b *Board) LegalMoves(playerColor Color) (moves []Move) {
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			moves = append(moves, b.stackMoves(x, y, playerColor)...)
+		}
+	}
+	if *b.GetReserves(playerColor) > 0 {
+		for x := 0; x < 8; x++ {
+			for y := 0; y < 8; y++ {
+				if b.Tiles[x][y].useable {
+					moves = append(moves, Move{X: x, Y: y, FromReserve: true})
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// HasLegalMove is a cheaper form of len(b.LegalMoves(playerColor)) > 0.
+func (b *Board) HasLegalMove(playerColor Color) bool {
+	if *b.GetReserves(playerColor) > 0 {
+		return true
+	}
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			if len(b.stackMoves(x, y, playerColor)) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (b *Board) canMoveStack(tile *Tile, playerColor Color) bool {
+	if !tile.useable || tile.Height() == 0 {
+		return false
+	}
+	for i := 0; i < len(tile.Pieces); i++ {
+		if tile.Pieces[i].Exists && tile.Pieces[i].Color != playerColor {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Board) stackMoves(x int, y int, playerColor Color) (moves []Move) {
+	tile := &b.Tiles[x][y]
+	if !b.canMoveStack(tile, playerColor) {
+		return nil
+	}
+	height := tile.Height()
+	for pieces := 1; pieces <= height; pieces++ {
+		// Every direction moves the stack one tile, so n pieces can reach any tile within n steps with the same parity as n.
+		for dx := -pieces; dx <= pieces; dx++ {
+			for dy := -pieces; dy <= pieces; dy++ {
+				distance := abs(dx) + abs(dy)
+				if distance == 0 || distance > pieces || (pieces-distance)%2 != 0 {
+					continue
+				}
+				destination, err := b.GetTile(x+dx, y+dy)
+				if err != nil || !destination.useable {
+					continue
+				}
+				moves = append(moves, Move{X: x, Y: y, Pieces: pieces, Directions: directionsFor(dx, dy, pieces)})
+			}
+		}
+	}
+	return moves
+}
+
+// StackMove makes a move of pieces from x, y to toX, toY.
+func StackMove(x int, y int, pieces int, toX int, toY int) Move {
+	return Move{X: x, Y: y, Pieces: pieces, Directions: directionsFor(toX-x, toY-y, pieces)}
+}
+
+// directionsFor builds a list of directions that adds up to dx, dy, padded with pairs that cancel out.
+func directionsFor(dx int, dy int, pieces int) []Direction {
+	directions := make([]Direction, 0, pieces)
+	for i := 0; i < abs(dx); i++ {
+		if dx > 0 {
+			directions = append(directions, RIGHT)
+		} else {
+			directions = append(directions, LEFT)
+		}
+	}
+	for i := 0; i < abs(dy); i++ {
+		if dy > 0 {
+			directions = append(directions, DOWN)
+		} else {
+			directions = append(directions, UP)
+		}
+	}
+	for len(directions) < pieces {
+		directions = append(directions, UP, DOWN)
+	}
+	return directions
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
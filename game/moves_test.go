@@ -0,0 +1,140 @@
+package game
+
+import "testing"
+
+func TestNewStartingBoard(t *testing.T) {
+	b := NewStartingBoard()
+	counts := map[Color]int{}
+	runForEveryTile(func(i int, j int) {
+		tile := b.Tiles[i][j]
+		if i == 0 || i == 7 || j == 0 || j == 7 {
+			if tile.Height() != 0 {
+				t.Errorf("Expected empty tile at %d, %d", i, j)
+			}
+			return
+		}
+		if tile.Height() != 1 {
+			t.Errorf("Expected 1 piece at %d, %d, got %d", i, j, tile.Height())
+		}
+		counts[tile.Top().Color]++
+	})
+	if counts[RED] != 18 || counts[GREEN] != 18 {
+		t.Errorf("Expected 18 pieces each, got %v", counts)
+	}
+	if b.Tiles[1][1].Top().Color != RED || b.Tiles[3][1].Top().Color != GREEN || b.Tiles[1][2].Top().Color != GREEN {
+		t.Errorf("Expected alternating pairs, got %v", b.Tiles)
+	}
+}
+
+func TestDirectionsFor(t *testing.T) {
+	for pieces := 1; pieces <= 5; pieces++ {
+		for dx := -pieces; dx <= pieces; dx++ {
+			for dy := -pieces; dy <= pieces; dy++ {
+				if abs(dx)+abs(dy) > pieces || (pieces-abs(dx)-abs(dy))%2 != 0 {
+					continue
+				}
+				directions := directionsFor(dx, dy, pieces)
+				if len(directions) != pieces {
+					t.Errorf("Expected %d directions, got %v", pieces, directions)
+				}
+				x, y := Move{X: 3, Y: 3, Directions: directions}.Destination()
+				if x != 3+dx || y != 3+dy {
+					t.Errorf("Expected destination %d, %d, got %d, %d", 3+dx, 3+dy, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestLegalMovesSinglePiece(t *testing.T) {
+	b := NewBoard()
+	err := b.AddPiece(3, 3, Piece{Color: RED, Exists: true}, RED)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	moves := b.LegalMoves(RED)
+	if len(moves) != 4 {
+		t.Errorf("Expected 4 moves, got %d", len(moves))
+	}
+	if len(b.LegalMoves(GREEN)) != 0 {
+		t.Errorf("Expected no moves for GREEN, got %v", b.LegalMoves(GREEN))
+	}
+	if b.HasLegalMove(GREEN) {
+		t.Errorf("Expected GREEN to have no legal move")
+	}
+}
+
+func TestLegalMovesCorner(t *testing.T) {
+	b := NewBoard()
+	err := b.AddPiece(0, 2, Piece{Color: RED, Exists: true}, RED)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	// Up is unusable and left is out of bounds.
+	moves := b.LegalMoves(RED)
+	if len(moves) != 2 {
+		t.Errorf("Expected 2 moves, got %v", moves)
+	}
+}
+
+func TestLegalMovesAreLegal(t *testing.T) {
+	b := NewStartingBoard()
+	b.SetReserves(GREEN, 1)
+	for _, color := range []Color{RED, GREEN} {
+		for _, move := range b.LegalMoves(color) {
+			copied := b
+			err := copied.Apply(move, color)
+			if err != nil {
+				t.Errorf("Expected no error for %v, got %v", move, err)
+			}
+		}
+	}
+	// 18 single pieces with 4 moves each, except the two in the corners which can't move into the corner triangles.
+	if len(b.LegalMoves(RED)) != 68 {
+		t.Errorf("Expected 68 moves, got %d", len(b.LegalMoves(RED)))
+	}
+	// Plus one reserve placement on each of the 52 useable tiles.
+	if len(b.LegalMoves(GREEN)) != 68+52 {
+		t.Errorf("Expected 120 moves, got %d", len(b.LegalMoves(GREEN)))
+	}
+}
+
+func TestApplyFromReserves(t *testing.T) {
+	b := NewBoard()
+	err := b.Apply(Move{X: 3, Y: 3, FromReserve: true}, RED)
+	if err != ErrNoReserves {
+		t.Errorf("Expected ErrNoReserves, got %v", err)
+	}
+	b.SetReserves(RED, 1)
+	err = b.Apply(Move{X: 0, Y: 0, FromReserve: true}, RED)
+	if err != ErrTileDestinationUnusable {
+		t.Errorf("Expected ErrTileDestinationUnusable, got %v", err)
+	}
+	err = b.Apply(Move{X: 3, Y: 3, FromReserve: true}, RED)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if b.ReservesR != 0 {
+		t.Errorf("Expected 0 RED reserves, got %d", b.ReservesR)
+	}
+	if b.Tiles[3][3].Top() != (Piece{Color: RED, Exists: true}) {
+		t.Errorf("Expected a red piece at 3, 3, got %v", b.Tiles[3][3])
+	}
+}
+
+func TestMoveEqual(t *testing.T) {
+	a := Move{X: 3, Y: 3, Pieces: 2, Directions: []Direction{UP, RIGHT}}
+	b := Move{X: 3, Y: 3, Pieces: 2, Directions: []Direction{RIGHT, UP}}
+	if !a.Equal(b) {
+		t.Errorf("Expected %v to equal %v", a, b)
+	}
+	if !a.Equal(StackMove(3, 3, 2, 4, 2)) {
+		t.Errorf("Expected %v to equal %v", a, StackMove(3, 3, 2, 4, 2))
+	}
+	if a.Equal(Move{X: 3, Y: 3, Pieces: 2, Directions: []Direction{UP, LEFT}}) {
+		t.Errorf("Expected moves to different tiles to differ")
+	}
+	if a.Equal(Move{X: 3, Y: 3, FromReserve: true}) {
+		t.Errorf("Expected a reserve placement to differ from a stack move")
+	}
+}
@@ -0,0 +1,216 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tiles are named by column a-h (x 0-7) and row 1-8 (y 0-7), so the top left corner is a1.
+//
+// A stack move is written from-to, with the number of pieces after a slash if it is more than one: c3-e3/2.
+// A placement from reserves is written with a star: *c3.
+//
+// A position is written as 8 rows from y 0 to 7, separated by slashes, followed by whose turn it is and the red and green reserves.
+// In each row, x is an unusable tile, a digit is that many empty tiles, r and g are single pieces,
+// and taller stacks are listed bottom to top in brackets: [rgr].
+const StartingPosition = "xx4xx/xrrggrrx/1ggrrgg1/1rrggrr1/1ggrrgg1/1rrggrr1/xggrrggx/xx4xx r 0 0"
+
+var (
+	ErrInvalidTile     = errors.New("Tiles are written as a column a-h followed by a row 1-8")
+	ErrInvalidMove     = errors.New("Moves are written as from-to, from-to/pieces, or *to for a reserve piece")
+	ErrInvalidPosition = errors.New("Positions are written as 8 rows separated by slashes, whose turn it is, then the red and green reserves")
+)
+
+func TileName(x int, y int) string {
+	return fmt.Sprintf("%c%d", 'a'+x, y+1)
+}
+
+func ParseTile(name string) (x int, y int, err error) {
+	if len(name) != 2 || name[0] < 'a' || name[0] > 'h' || name[1] < '1' || name[1] > '8' {
+		return 0, 0, ErrInvalidTile
+	}
+	return int(name[0] - 'a'), int(name[1] - '1'), nil
+}
+
+func (m Move) String() string {
+	if m.FromReserve {
+		return "*" + TileName(m.X, m.Y)
+	}
+	x, y := m.Destination()
+	s := TileName(m.X, m.Y) + "-" + TileName(x, y)
+	if m.Pieces != 1 {
+		s += "/" + strconv.Itoa(m.Pieces)
+	}
+	return s
+}
+
+func ParseMove(s string) (m Move, err error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "*") {
+		x, y, err := ParseTile(s[1:])
+		if err != nil {
+			return m, err
+		}
+		return Move{X: x, Y: y, FromReserve: true}, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return m, ErrInvalidMove
+	}
+	pieces := 1
+	if destination, count, ok := strings.Cut(to, "/"); ok {
+		pieces, err = strconv.Atoi(count)
+		if err != nil || pieces < 1 || pieces > 5 {
+			return m, ErrInvalidMove
+		}
+		to = destination
+	}
+	x, y, err := ParseTile(from)
+	if err != nil {
+		return m, err
+	}
+	toX, toY, err := ParseTile(to)
+	if err != nil {
+		return m, err
+	}
+	distance := abs(toX-x) + abs(toY-y)
+	if distance == 0 || distance > pieces || (pieces-distance)%2 != 0 {
+		return m, fmt.Errorf("%d pieces can't move from %s to %s", pieces, from, to)
+	}
+	return StackMove(x, y, pieces, toX, toY), nil
+}
+
+func colorLetter(c Color) byte {
+	if c == RED {
+		return 'r'
+	}
+	return 'g'
+}
+
+// FormatPosition writes the board with turn to move in the notation described above.
+func FormatPosition(b Board, turn Color) string {
+	var s strings.Builder
+	for y := 0; y < 8; y++ {
+		if y > 0 {
+			s.WriteByte('/')
+		}
+		empty := 0
+		for x := 0; x < 8; x++ {
+			tile := &b.Tiles[x][y]
+			if tile.useable && tile.Height() == 0 {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				s.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			if !tile.useable {
+				s.WriteByte('x')
+				continue
+			}
+			if tile.Height() > 1 {
+				s.WriteByte('[')
+			}
+			for _, piece := range tile.Pieces {
+				if piece.Exists {
+					s.WriteByte(colorLetter(piece.Color))
+				}
+			}
+			if tile.Height() > 1 {
+				s.WriteByte(']')
+			}
+		}
+		if empty > 0 {
+			s.WriteString(strconv.Itoa(empty))
+		}
+	}
+	fmt.Fprintf(&s, " %c %d %d", colorLetter(turn), b.ReservesR, b.ReservesG)
+	return s.String()
+}
+
+// ParsePosition reads a position written by FormatPosition. "start" is accepted for StartingPosition.
+func ParsePosition(s string) (b Board, turn Color, err error) {
+	if strings.TrimSpace(s) == "start" {
+		s = StartingPosition
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 4 {
+		return b, turn, ErrInvalidPosition
+	}
+	rows := strings.Split(fields[0], "/")
+	if len(rows) != 8 {
+		return b, turn, ErrInvalidPosition
+	}
+	for y, row := range rows {
+		x := 0
+		for i := 0; i < len(row); i++ {
+			if x > 7 {
+				return b, turn, fmt.Errorf("Row %d has more than 8 tiles", y+1)
+			}
+			c := row[i]
+			switch {
+			case c >= '1' && c <= '8':
+				if x+int(c-'0') > 8 {
+					return b, turn, fmt.Errorf("Row %d has more than 8 tiles", y+1)
+				}
+				for n := 0; n < int(c-'0'); n++ {
+					b.Tiles[x][y] = Tile{useable: true}
+					x++
+				}
+				continue
+			case c == 'x':
+				b.Tiles[x][y] = Tile{useable: false}
+			case c == 'r' || c == 'g':
+				b.Tiles[x][y] = Tile{useable: true}
+				b.Tiles[x][y].Pieces[0] = Piece{Color: letterColor(c), Exists: true}
+			case c == '[':
+				end := strings.IndexByte(row[i:], ']')
+				// end is the index of the closing bracket, so a stack of n pieces has end n+1.
+				if end < 3 || end > 6 {
+					return b, turn, fmt.Errorf("Stack at %s should be 2 to 5 pieces in brackets", TileName(x, y))
+				}
+				b.Tiles[x][y] = Tile{useable: true}
+				for k, letter := range []byte(row[i+1 : i+end]) {
+					if letter != 'r' && letter != 'g' {
+						return b, turn, fmt.Errorf("Stack at %s should only have r and g pieces", TileName(x, y))
+					}
+					b.Tiles[x][y].Pieces[k] = Piece{Color: letterColor(letter), Exists: true}
+				}
+				i += end
+			default:
+				return b, turn, fmt.Errorf("Unexpected %q in row %d", c, y+1)
+			}
+			x++
+		}
+		if x != 8 {
+			return b, turn, fmt.Errorf("Row %d should have 8 tiles, got %d", y+1, x)
+		}
+	}
+	switch fields[1] {
+	case "r":
+		turn = RED
+	case "g":
+		turn = GREEN
+	default:
+		return b, turn, ErrInvalidPosition
+	}
+	b.ReservesR, err = strconv.Atoi(fields[2])
+	if err != nil || b.ReservesR < 0 {
+		return b, turn, ErrInvalidPosition
+	}
+	b.ReservesG, err = strconv.Atoi(fields[3])
+	if err != nil || b.ReservesG < 0 {
+		return b, turn, ErrInvalidPosition
+	}
+	return b, turn, nil
+}
+
+func letterColor(c byte) Color {
+	if c == 'r' {
+		return RED
+	}
+	return GREEN
+}
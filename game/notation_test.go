@@ -0,0 +1,53 @@
+package game
+
+import "testing"
+
+func TestPositionRoundTrip(t *testing.T) {
+	if FormatPosition(NewStartingBoard(), RED) != StartingPosition {
+		t.Errorf("Expected the starting board to be %q, got %q", StartingPosition, FormatPosition(NewStartingBoard(), RED))
+	}
+	b, turn, err := ParsePosition(tallStacks)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if turn != GREEN || b.ReservesR != 2 || b.ReservesG != 1 {
+		t.Errorf("Expected GREEN to move with reserves 2 and 1, got %v with %d and %d", turn, b.ReservesR, b.ReservesG)
+	}
+	if b.Tiles[1][1].Height() != 5 || b.Tiles[3][4].Top().Color != GREEN || b.Tiles[3][4].Pieces[0].Color != RED {
+		t.Errorf("Expected stacks to be read bottom to top, got %v", b.Tiles)
+	}
+	if FormatPosition(b, turn) != tallStacks {
+		t.Errorf("Expected %q, got %q", tallStacks, FormatPosition(b, turn))
+	}
+	start := NewStartingBoard()
+	if b.Hash(turn) == start.Hash(RED) {
+		t.Errorf("Expected a different position to hash differently")
+	}
+	for _, invalid := range []string{"", "start r", "xx4xx/xx4xx r 0 0", "xx5xx/xrrggrrx/1ggrrgg1/1rrggrr1/1ggrrgg1/1rrggrr1/xggrrggx/xx4xx r 0 0", "xx4xx/x[r]rggrrx/1ggrrgg1/1rrggrr1/1ggrrgg1/1rrggrr1/xggrrggx/xx4xx r 0 0", "xx4xx/xrrggrrx/1ggrrgg1/1rrggrr1/1ggrrgg1/1rrggrr1/xggrrggx/xx4xx b 0 0"} {
+		if _, _, err := ParsePosition(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestMoveNotation(t *testing.T) {
+	b := NewStartingBoard()
+	for _, move := range append(b.LegalMoves(RED), Move{X: 2, Y: 5, FromReserve: true}) {
+		parsed, err := ParseMove(move.String())
+		if err != nil {
+			t.Errorf("Expected to parse %v, got %v", move, err)
+			continue
+		}
+		if !parsed.Equal(move) {
+			t.Errorf("Expected %v, got %v", move, parsed)
+		}
+	}
+	if m, _ := ParseMove("c3-e3/2"); m.X != 2 || m.Y != 2 || m.Pieces != 2 {
+		t.Errorf("Expected 2 pieces from 2, 2, got %+v", m)
+	}
+	for _, invalid := range []string{"c3", "c3-e3", "c3-c3", "c3-c4/2", "c3-c5/6", "i1-a1", "*a9"} {
+		if _, err := ParseMove(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package game
+
+// Perft counts the positions reached after exactly depth moves from the board, with turn to move first.
+// Games that end sooner don't count towards the total. It is for checking move generation against known counts.
+func Perft(b Board, turn Color, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	moves := b.LegalMoves(turn)
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+	var nodes uint64
+	for _, move := range moves {
+		child := b
+		child.Apply(move, turn)
+		nodes += Perft(child, turn.Opponent(), depth-1)
+	}
+	return nodes
+}
+
+// A DivideEntry is the perft count below one move from the root.
+type DivideEntry struct {
+	Move  Move
+	Nodes uint64
+}
+
+// Divide splits Perft by the first move, in the order LegalMoves lists them, which helps find where two move generators disagree.
+func Divide(b Board, turn Color, depth int) (entries []DivideEntry) {
+	if depth < 1 {
+		return nil
+	}
+	for _, move := range b.LegalMoves(turn) {
+		child := b
+		child.Apply(move, turn)
+		entries = append(entries, DivideEntry{Move: move, Nodes: Perft(child, turn.Opponent(), depth-1)})
+	}
+	return entries
+}
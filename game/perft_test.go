@@ -0,0 +1,97 @@
+package game
+
+import "testing"
+
+// tallStacks has full stacks next to others, so moves overflow into the reserves and off the board.
+const tallStacks = "xx4xx/x[rrrrr]r4x/1[gg][ggg]3g1/8/3[rgrg]4/8/x6x/xx4xx g 2 1"
+
+func TestPerft(t *testing.T) {
+	tests := []struct {
+		position string
+		counts   []uint64
+	}{
+		{position: StartingPosition, counts: []uint64{68, 4460, 285074, 17612388}},
+		{position: tallStacks, counts: []uint64{89, 8666, 498257}},
+	}
+	for _, test := range tests {
+		b, turn, err := ParsePosition(test.position)
+		if err != nil {
+			t.Fatalf("Expected to parse %q, got %v", test.position, err)
+		}
+		for i, expected := range test.counts {
+			if nodes := Perft(b, turn, i+1); nodes != expected {
+				t.Errorf("Expected perft %d of %q to be %d, got %d", i+1, test.position, expected, nodes)
+			}
+		}
+	}
+}
+
+func TestDivide(t *testing.T) {
+	b := NewStartingBoard()
+	entries := Divide(b, RED, 2)
+	if len(entries) != 68 {
+		t.Fatalf("Expected 68 root moves, got %d", len(entries))
+	}
+	var total uint64
+	for _, entry := range entries {
+		total += entry.Nodes
+	}
+	if total != Perft(b, RED, 2) {
+		t.Errorf("Expected the divide counts to add up to %d, got %d", Perft(b, RED, 2), total)
+	}
+}
+
+// bruteMoves finds every move by trying every list of directions with Board.Move, as a check on LegalMoves.
+func bruteMoves(b Board, turn Color) map[string]bool {
+	moves := map[string]bool{}
+	runForEveryTile(func(x int, y int) {
+		for pieces := 1; pieces <= 5; pieces++ {
+			directions := make([]Direction, pieces)
+			combinations := 1 << (2 * pieces)
+			for c := 0; c < combinations; c++ {
+				for i := range directions {
+					directions[i] = []Direction{UP, DOWN, LEFT, RIGHT}[(c>>(2*i))&3]
+				}
+				child := b
+				if child.Move(x, y, pieces, directions, turn) != nil {
+					continue
+				}
+				move := Move{X: x, Y: y, Pieces: pieces, Directions: directions}
+				if toX, toY := move.Destination(); toX != x || toY != y {
+					moves[move.String()] = true
+				}
+			}
+		}
+		if b.Tiles[x][y].useable && *b.GetReserves(turn) > 0 {
+			moves[Move{X: x, Y: y, FromReserve: true}.String()] = true
+		}
+	})
+	return moves
+}
+
+func TestLegalMovesMatchBruteForce(t *testing.T) {
+	for _, position := range []string{StartingPosition, tallStacks} {
+		b, turn, err := ParsePosition(position)
+		if err != nil {
+			t.Fatalf("Expected to parse %q, got %v", position, err)
+		}
+		// Check every position two moves deep.
+		for _, move := range append(b.LegalMoves(turn), Move{}) {
+			child, childTurn := b, turn
+			if move.Pieces > 0 || move.FromReserve {
+				child.Apply(move, turn)
+				childTurn = turn.Opponent()
+			}
+			expected := bruteMoves(child, childTurn)
+			moves := child.LegalMoves(childTurn)
+			if len(moves) != len(expected) {
+				t.Errorf("Expected %d moves in %q, got %d", len(expected), FormatPosition(child, childTurn), len(moves))
+			}
+			for _, m := range moves {
+				if !expected[m.String()] {
+					t.Errorf("Expected %v not to be legal in %q", m, FormatPosition(child, childTurn))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+type Result int
+
+const (
+	UNDECIDED Result = iota
+	RED_WON
+	GREEN_WON
+	DRAW
+)
+
+func (r Result) String() string {
+	switch r {
+	case RED_WON:
+		return "RED_WON"
+	case GREEN_WON:
+		return "GREEN_WON"
+	case DRAW:
+		return "DRAW"
+	}
+	return "UNDECIDED"
+}
+
+// WinFor returns the result of a game won by color.
+func WinFor(color Color) Result {
+	if color == RED {
+		return RED_WON
+	}
+	return GREEN_WON
+}
+
+// Record is a full game: the position it started from, every move played in turn and how it ended.
+type Record struct {
+	Red    string
+	Green  string
+	Start  Board
+	Turn   Color
+	Moves  []Move
+	Result Result
+	Reason string
+}
+
+// Positions replays the record, returning the board before every move and the board after the last one.
+func (r *Record) Positions() (boards []Board, err error) {
+	board := r.Start
+	turn := r.Turn
+	boards = append(boards, board)
+	for _, move := range r.Moves {
+		err = board.Apply(move, turn)
+		if err != nil {
+			return boards, err
+		}
+		turn = turn.Opponent()
+		boards = append(boards, board)
+	}
+	return boards, nil
+}
+
+// TurnAt returns whose move it is after ply moves have been played.
+func (r *Record) TurnAt(ply int) Color {
+	if ply%2 == 0 {
+		return r.Turn
+	}
+	return r.Turn.Opponent()
+}
+
+// WriteRecord writes a record as a single line of JSON, so that many games can be appended to one file.
+func WriteRecord(w io.Writer, r Record) (err error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// ReadRecords reads every record written by WriteRecord.
+func ReadRecords(r io.Reader) (records []Record, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		err = json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+type tileJSON struct {
+	Useable bool
+	Pieces  [5]Piece
+}
+
+func (t Tile) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tileJSON{Useable: t.useable, Pieces: t.Pieces})
+}
+
+func (t *Tile) UnmarshalJSON(data []byte) (err error) {
+	var tj tileJSON
+	err = json.Unmarshal(data, &tj)
+	if err != nil {
+		return err
+	}
+	t.useable = tj.Useable
+	t.Pieces = tj.Pieces
+	return nil
+}
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	record := Record{
+		Red:   "a",
+		Green: "b",
+		Start: NewStartingBoard(),
+		Turn:  RED,
+		Moves: []Move{
+			{X: 1, Y: 1, Pieces: 1, Directions: []Direction{RIGHT}},
+			{X: 3, Y: 1, Pieces: 1, Directions: []Direction{UP}},
+		},
+		Result: DRAW,
+	}
+	var buf bytes.Buffer
+	err := WriteRecord(&buf, record)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err = WriteRecord(&buf, record)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	records, err := ReadRecords(&buf)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0].Start != record.Start {
+		t.Errorf("Expected the starting board to survive, got %v", records[0].Start)
+	}
+	if !records[0].Start.Tiles[1][1].Useable() || records[0].Start.Tiles[0][0].Useable() {
+		t.Errorf("Expected useable tiles to survive")
+	}
+	if records[1].Result != DRAW || len(records[1].Moves) != 2 {
+		t.Errorf("Expected %v, got %v", record, records[1])
+	}
+}
+
+func TestRecordPositions(t *testing.T) {
+	record := Record{
+		Start: NewStartingBoard(),
+		Turn:  RED,
+		Moves: []Move{
+			{X: 1, Y: 1, Pieces: 1, Directions: []Direction{RIGHT}},
+			{X: 3, Y: 1, Pieces: 1, Directions: []Direction{UP}},
+		},
+	}
+	boards, err := record.Positions()
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(boards) != 3 {
+		t.Fatalf("Expected 3 boards, got %d", len(boards))
+	}
+	if boards[1].Tiles[2][1].Height() != 2 {
+		t.Errorf("Expected a stack of 2 at 2, 1, got %v", boards[1].Tiles[2][1])
+	}
+	if boards[2].Tiles[3][0].Height() != 1 {
+		t.Errorf("Expected a piece at 3, 0, got %v", boards[2].Tiles[3][0])
+	}
+	if record.TurnAt(1) != GREEN {
+		t.Errorf("Expected GREEN, got %v", record.TurnAt(1))
+	}
+
+	record.Moves = append(record.Moves, Move{X: 0, Y: 0, Pieces: 1, Directions: []Direction{UP}})
+	_, err = record.Positions()
+	if err != ErrTileSourceNonUsable {
+		t.Errorf("Expected ErrTileSourceNonUsable, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/headblockhead/focus-ai/render"
+)
+
+// exportGIF draws every move of a recorded game into an animated GIF, for sharing games without the visualizer.
+func exportGIF(args []string) (err error) {
+	animation := render.DefaultAnimation()
+	flags := flag.NewFlagSet("gif", flag.ExitOnError)
+	records := flags.String("records", "", "File of game records to draw a game from")
+	index := flags.Int("game", 0, "Which game in the file to draw, counting from 0, or from the end if negative")
+	out := flags.String("out", "game.gif", "File to write the GIF to")
+	flags.IntVar(&animation.TileSize, "tile", animation.TileSize, "Width of each tile in pixels")
+	flags.DurationVar(&animation.Delay, "delay", animation.Delay, "Time each move is shown for")
+	spec := flags.String("eval", "", "Agent to evaluate every position with for the captions, or empty for none")
+	limits := agent.SearchLimits{MoveTime: 200 * time.Millisecond}
+	limitFlags(flags, &limits)
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *records == "" {
+		return fmt.Errorf("-records is required")
+	}
+	f, err := os.Open(*records)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	games, err := game.ReadRecords(f)
+	if err != nil {
+		return err
+	}
+	i := *index
+	if i < 0 {
+		i += len(games)
+	}
+	if i < 0 || i >= len(games) {
+		return fmt.Errorf("%s has %d games, so there is no game %d", *records, len(games), *index)
+	}
+	record := games[i]
+
+	if *spec != "" {
+		a, closeAgent, err := newAgent(*spec)
+		if err != nil {
+			return err
+		}
+		defer closeAgent()
+		boards, err := record.Positions()
+		if err != nil {
+			return err
+		}
+		captions := make([]string, len(boards))
+		for ply, board := range boards {
+			captions[ply] = render.Caption(record, ply)
+			turn := record.TurnAt(ply)
+			if !board.HasLegalMove(turn) {
+				continue
+			}
+			// The last score reported is the one the agent settled on. Reports can come from any goroutine.
+			var mutex sync.Mutex
+			var last *agent.Info
+			ctx := agent.WithInfo(agent.WithLimits(context.Background(), limits), func(info agent.Info) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				last = &info
+			})
+			if _, err = a.SelectMove(ctx, board, turn); err != nil {
+				return err
+			}
+			mutex.Lock()
+			if last != nil {
+				captions[ply] += fmt.Sprintf(", %+.2f for %v", last.Score, turn)
+			}
+			mutex.Unlock()
+		}
+		animation.Caption = func(ply int) string {
+			return captions[ply]
+		}
+	}
+
+	w, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err = render.GIF(w, record, animation); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -2,12 +2,42 @@ module github.com/headblockhead/focus-ai
 
 require (
 	github.com/hajimehoshi/ebiten/v2 v2.5.0
+	github.com/headblockhead/focus-ai/agent v0.0.0
+	github.com/headblockhead/focus-ai/arena v0.0.0
+	github.com/headblockhead/focus-ai/book v0.0.0
+	github.com/headblockhead/focus-ai/eval v0.0.0
 	github.com/headblockhead/focus-ai/game v0.0.0
+	github.com/headblockhead/focus-ai/protocol v0.0.0
+	github.com/headblockhead/focus-ai/render v0.0.0
+	github.com/headblockhead/focus-ai/search v0.0.0
+	github.com/headblockhead/focus-ai/server v0.0.0
+	github.com/headblockhead/focus-ai/tablebase v0.0.0
+	github.com/headblockhead/focus-ai/tui v0.0.0
 	github.com/headblockhead/focus-ai/visualizer v0.0.0
 )
 
 replace github.com/headblockhead/focus-ai/game v0.0.0 => ./game
 
+replace github.com/headblockhead/focus-ai/eval v0.0.0 => ./eval
+
+replace github.com/headblockhead/focus-ai/agent v0.0.0 => ./agent
+
+replace github.com/headblockhead/focus-ai/arena v0.0.0 => ./arena
+
+replace github.com/headblockhead/focus-ai/book v0.0.0 => ./book
+
+replace github.com/headblockhead/focus-ai/protocol v0.0.0 => ./protocol
+
+replace github.com/headblockhead/focus-ai/render v0.0.0 => ./render
+
+replace github.com/headblockhead/focus-ai/search v0.0.0 => ./search
+
+replace github.com/headblockhead/focus-ai/server v0.0.0 => ./server
+
+replace github.com/headblockhead/focus-ai/tablebase v0.0.0 => ./tablebase
+
+replace github.com/headblockhead/focus-ai/tui v0.0.0 => ./tui
+
 replace github.com/headblockhead/focus-ai/visualizer v0.0.0 => ./visualizer
 
 require (
@@ -19,8 +49,9 @@ require (
 	golang.org/x/exp/shiny v0.0.0-20230321023759-10a507213a29 // indirect
 	golang.org/x/image v0.6.0 // indirect
 	golang.org/x/mobile v0.0.0-20230301163155-e0f57694e12c // indirect
+	golang.org/x/net v0.17.0 // indirect
 	golang.org/x/sync v0.1.0 // indirect
-	golang.org/x/sys v0.6.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
 )
 
 go 1.20
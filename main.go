@@ -1,22 +1,60 @@
 package main
 
 import (
-	"github.com/headblockhead/focus-ai/game"
-	"github.com/headblockhead/focus-ai/visualizer"
-
-	"github.com/hajimehoshi/ebiten/v2"
+	"fmt"
+	"os"
+	"sort"
 )
 
-func main() {
-	ebiten.SetWindowTitle("Focus AI Visualizer")
-	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+type command struct {
+	run     func(args []string) error
+	summary string
+}
 
-	board := game.NewBoard()
+var commands = map[string]command{
+	"visualize":  {visualize, "Open the visualizer window, if built with -tags visualizer"},
+	"play":       {play, "Play a game against agents or other people"},
+	"replay":     {replay, "Step through a recorded game in the visualizer"},
+	"selfplay":   {selfplay, "Have agents play each other and save the records"},
+	"train":      {train, "Tune the evaluation weights on recorded games"},
+	"tournament": {tournament, "Play agents against each other and rate them"},
+	"perft":      {perft, "Count the move tree below a position"},
+	"render":     {renderPosition, "Draw a position to a PNG"},
+	"analyze":    {analyze, "Have an agent search a position, showing what it thinks"},
+	"serve":      {serve, "Play games over HTTP"},
+	"gif":        {exportGIF, "Draw a recorded game to an animated GIF"},
+	"engine":     {engine, "Run an agent over the engine protocol on standard input and output"},
+	"tablebase":  {buildTablebase, "Build or probe an endgame tablebase"},
+	"book":       {buildBook, "Build an opening book from recorded games"},
+}
 
-	vis := visualizer.NewVisualizer(&board)
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: focus-ai <command> [flags]")
+	fmt.Fprintln(os.Stderr, "Every command takes -config, a JSON file of flag values. Give -h after a command for its flags.")
+	fmt.Fprintln(os.Stderr)
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-11s %s\n", name, commands[name].summary)
+	}
+}
 
-	// An ungraceful quit
-	if err := ebiten.RunGame(vis); err != nil && err.Error() != "quit" {
-		panic(err)
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		usage()
+		return
+	}
+	c, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		fmt.Fprintf(os.Stderr, "\nUnknown command %q\n", os.Args[1])
+		os.Exit(2)
+	}
+	if err := c.run(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/headblockhead/focus-ai/book"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// buildBook builds an opening book from recorded games.
+func buildBook(args []string) (err error) {
+	config := book.DefaultBuildConfig()
+	flags := flag.NewFlagSet("book", flag.ExitOnError)
+	records := flags.String("records", "", "File of game records to build the book from")
+	out := flags.String("out", "book.bin", "File to write the book to")
+	flags.IntVar(&config.MaxPlies, "maxplies", config.MaxPlies, "Moves into each game to add to the book")
+	flags.IntVar(&config.MinGames, "mingames", config.MinGames, "Games a position must be reached in to stay in the book")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *records == "" {
+		return fmt.Errorf("-records is required")
+	}
+	f, err := os.Open(*records)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	games, err := game.ReadRecords(f)
+	if err != nil {
+		return err
+	}
+	openings, err := book.Build(games, config)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d positions from %d games\n", openings.Len(), len(games))
+	return book.Save(*out, openings)
+}
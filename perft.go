@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// perft counts the move tree below a position, to check move generation.
+func perft(args []string) (err error) {
+	flags := flag.NewFlagSet("perft", flag.ExitOnError)
+	divide := flags.Bool("divide", false, "List the count below each first move")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: focus-ai perft [-divide] <position> <depth>")
+		fmt.Fprintln(flags.Output(), "The position is \"start\" or written in position notation, in quotes.")
+		flags.PrintDefaults()
+	}
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("Expected a position and a depth")
+	}
+	board, turn, err := game.ParsePosition(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	depth, err := strconv.Atoi(flags.Arg(1))
+	if err != nil || depth < 0 {
+		return fmt.Errorf("Depth should be a number of moves, got %q", flags.Arg(1))
+	}
+
+	start := time.Now()
+	var nodes uint64
+	if *divide {
+		for _, entry := range game.Divide(board, turn, depth) {
+			fmt.Printf("%-8v %d\n", entry.Move, entry.Nodes)
+			nodes += entry.Nodes
+		}
+		fmt.Println()
+	} else {
+		nodes = game.Perft(board, turn, depth)
+	}
+	elapsed := time.Since(start)
+	fmt.Printf("Nodes: %d\nTime: %v\nNodes per second: %.0f\n", nodes, elapsed.Round(time.Millisecond), float64(nodes)/elapsed.Seconds())
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/headblockhead/focus-ai/tui"
+)
+
+// play plays a game against agents or other people, in the visualizer or, with -tui, in the terminal.
+func play(args []string) (err error) {
+	flags := flag.NewFlagSet("play", flag.ExitOnError)
+	text := flags.Bool("tui", false, "Play in the terminal instead of the visualizer")
+	red := flags.String("red", "human", "Who plays red, human or an agent such as alphabeta:depth=4")
+	green := flags.String("green", "alphabeta", "Who plays green, human or an agent")
+	position := flags.String("position", "start", "Position to start from")
+	records := flags.String("records", "", "File to append the game record to")
+	options := windowFlags(flags)
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	board, turn, err := game.ParsePosition(*position)
+	if err != nil {
+		return err
+	}
+	redPlayer, closeRed, err := newPlayer(*red)
+	if err != nil {
+		return err
+	}
+	defer closeRed()
+	greenPlayer, closeGreen, err := newPlayer(*green)
+	if err != nil {
+		return err
+	}
+	defer closeGreen()
+	if !*text {
+		return openWindow(board, turn, redPlayer, greenPlayer, *options)
+	}
+	record, err := tui.Play(context.Background(), os.Stdin, os.Stdout, board, turn, redPlayer, greenPlayer)
+	if errors.Is(err, tui.ErrAbandoned) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if *records == "" {
+		return nil
+	}
+	f, err := os.OpenFile(*records, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err = game.WriteRecord(f, record); err != nil {
+		return fmt.Errorf("Failed to save the game: %w", err)
+	}
+	return nil
+}
+
+// newPlayer builds the agent for a spec, or nil for a human.
+func newPlayer(spec string) (agent.Agent, func(), error) {
+	if spec == "human" {
+		return nil, func() {}, nil
+	}
+	return newAgent(spec)
+}
@@ -0,0 +1,206 @@
+package protocol
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// Client is an agent played by an engine on the other end of the protocol.
+// An engine can only think about one position at a time, so SelectMove calls take turns.
+type Client struct {
+	name  string
+	mutex sync.Mutex
+	in    io.Reader
+	out   io.Writer
+	lines chan string
+	// cmd is the engine's process, if the client started it.
+	cmd *exec.Cmd
+}
+
+// closeTimeout is how long an engine has to quit before it is killed.
+const closeTimeout = 5 * time.Second
+
+// NewClient talks to an engine that reads commands from w and answers on r. It waits for the engine to say it is ready, unless ctx is done first.
+// r and w are closed when the client is, if they can be.
+func NewClient(ctx context.Context, r io.Reader, w io.Writer) (c *Client, err error) {
+	return newClient(ctx, r, w, nil)
+}
+
+func newClient(ctx context.Context, r io.Reader, w io.Writer, cmd *exec.Cmd) (c *Client, err error) {
+	c = &Client{name: "engine", in: r, out: w, lines: make(chan string), cmd: cmd}
+	go func(lines chan string) {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}(c.lines)
+	if err = c.handshake(ctx); err != nil {
+		c.abandon()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) handshake(ctx context.Context) (err error) {
+	if err = c.send("fep"); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", ErrEngineTimeout, ctx.Err())
+		case line, ok := <-c.lines:
+			if !ok {
+				return ErrEngineClosed
+			}
+			if name, ok := strings.CutPrefix(line, "id name "); ok {
+				c.name = name
+			}
+			if line == "fepok" {
+				return nil
+			}
+		}
+	}
+}
+
+// Start runs an engine program and talks to it, giving up if it isn't ready before ctx is done. Anything it writes to standard error is passed on.
+func Start(ctx context.Context, command string, args ...string) (c *Client, err error) {
+	cmd := exec.Command(command, args...)
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	c, err = newClient(ctx, out, in, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", command, err)
+	}
+	return c, nil
+}
+
+func (c *Client) Name() string {
+	return c.name
+}
+
+func (c *Client) send(format string, args ...any) (err error) {
+	_, err = fmt.Fprintf(c.out, format+"\n", args...)
+	return err
+}
+
+// SelectMove sends the position and any limits attached to ctx, passing on the engine's info until it answers with a move.
+// If ctx is cancelled the engine is told to stop, and its answer is still waited for.
+// If the engine reports an error before answering, such as not accepting the position, the error is returned in place of its move.
+func (c *Client) SelectMove(ctx context.Context, board game.Board, playerColor game.Color) (move game.Move, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err = c.send("position %s", game.FormatPosition(board, playerColor)); err != nil {
+		return move, err
+	}
+	if err = c.send(formatGo(agent.LimitsFrom(ctx))); err != nil {
+		return move, err
+	}
+	done := ctx.Done()
+	// An error from the engine means its move isn't for this position, but it is still waited for, so the next search starts afresh.
+	var engineErr error
+	for {
+		select {
+		case <-done:
+			if err = c.send("stop"); err != nil {
+				return move, err
+			}
+			// Only stop once.
+			done = nil
+		case line, ok := <-c.lines:
+			if !ok {
+				return move, ErrEngineClosed
+			}
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			switch {
+			case fields[0] == "bestmove" && engineErr != nil:
+				return move, engineErr
+			case fields[0] == "bestmove" && fields[1] == "none":
+				return move, agent.ErrNoLegalMoves
+			case fields[0] == "bestmove":
+				return game.ParseMove(fields[1])
+			case fields[0] == "info" && fields[1] == "string":
+				if text, ok := strings.CutPrefix(line, "info string error: "); ok && engineErr == nil {
+					engineErr = fmt.Errorf("%w: %s", ErrEngineError, text)
+				}
+			case fields[0] == "info":
+				// Info the client can't read is skipped rather than ending the search.
+				if info, err := parseInfo(fields[1:]); err == nil {
+					agent.ReportInfo(ctx, info)
+				}
+			}
+		}
+	}
+}
+
+// Close tells the engine to quit and closes its input, then waits for it to stop answering, and for its process if the client started it.
+// An engine that hasn't quit within closeTimeout is killed.
+func (c *Client) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.send("quit")
+	if closer, ok := c.out.(io.Closer); ok {
+		closer.Close()
+	}
+	timeout := time.NewTimer(closeTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		// Anything said after the last move isn't wanted.
+		case _, ok := <-c.lines:
+			if !ok {
+				if c.cmd == nil {
+					return nil
+				}
+				return c.cmd.Wait()
+			}
+		case <-timeout.C:
+			c.abandon()
+			return ErrEngineTimeout
+		}
+	}
+}
+
+// abandon stops the engine without waiting for it to quit, killing its process, or closing what it answers on, if it can.
+// Whatever it still says is dropped, so nothing is left waiting to pass it on.
+func (c *Client) abandon() {
+	if closer, ok := c.out.(io.Closer); ok {
+		closer.Close()
+	}
+	if c.cmd != nil {
+		c.cmd.Process.Kill()
+	} else if closer, ok := c.in.(io.Closer); ok {
+		closer.Close()
+	}
+	go func() {
+		for range c.lines {
+		}
+		if c.cmd != nil {
+			c.cmd.Wait()
+		}
+	}()
+}
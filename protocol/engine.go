@@ -0,0 +1,213 @@
+package protocol
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+var (
+	errAlreadySearching = errors.New("Already searching")
+	errNoPosition       = errors.New("There is no position, as the last one couldn't be set up")
+)
+
+// engine answers commands for an agent.
+type engine struct {
+	agent agent.Agent
+	// mutex is held while writing a line, as searches write info from their own goroutines.
+	mutex sync.Mutex
+	out   io.Writer
+	board game.Board
+	turn  game.Color
+	// lost is set once a position or moves can't be set up, so that the position before them isn't searched by mistake.
+	lost bool
+	// stop and done are set while a search is running.
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// Serve reads commands from r and writes answers to w for the agent until it is sent quit, r ends, or ctx is cancelled.
+func Serve(ctx context.Context, a agent.Agent, r io.Reader, w io.Writer) error {
+	e := &engine{agent: a, out: w, board: game.NewStartingBoard(), turn: game.RED}
+	defer e.stopSearch()
+
+	lines := make(chan string)
+	scanErr := make(chan error, 1)
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-finished:
+				return
+			}
+		}
+		scanErr <- scanner.Err()
+		close(lines)
+	}()
+
+	for {
+		var line string
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line, ok = <-lines:
+			if !ok {
+				return <-scanErr
+			}
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var err error
+		switch fields[0] {
+		case "fep":
+			e.send("id name %s", a.Name())
+			e.send("fepok")
+		case "isready":
+			e.send("readyok")
+		case "newgame":
+			e.stopSearch()
+			e.board, e.turn, e.lost = game.NewStartingBoard(), game.RED, false
+		case "position":
+			e.stopSearch()
+			err = e.position(fields[1:])
+			e.lost = err != nil
+		case "moves":
+			e.stopSearch()
+			if err = e.play(fields[1:]); err != nil {
+				e.lost = true
+			}
+		case "go":
+			// The client waits for a move, so it is still answered, after the error.
+			if err = e.search(ctx, fields[1:]); err != nil && err != errAlreadySearching {
+				e.send("info string error: %v", err)
+				e.send("bestmove none")
+				err = nil
+			}
+		case "stop":
+			e.stopSearch()
+		case "quit":
+			return nil
+		}
+		if err != nil {
+			e.send("info string error: %v", err)
+		}
+	}
+}
+
+func (e *engine) send(format string, args ...any) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	fmt.Fprintf(e.out, format+"\n", args...)
+}
+
+// position reads "start" or a position, which takes 4 fields, followed by any moves.
+func (e *engine) position(fields []string) (err error) {
+	var rest []string
+	if len(fields) > 0 && fields[0] == "start" {
+		e.board, e.turn, e.lost = game.NewStartingBoard(), game.RED, false
+		rest = fields[1:]
+	} else {
+		if len(fields) < 4 {
+			return game.ErrInvalidPosition
+		}
+		board, turn, err := game.ParsePosition(strings.Join(fields[:4], " "))
+		if err != nil {
+			return err
+		}
+		e.board, e.turn, e.lost = board, turn, false
+		rest = fields[4:]
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	if rest[0] != "moves" {
+		return fmt.Errorf("Expected moves after the position, got %q", rest[0])
+	}
+	return e.play(rest[1:])
+}
+
+func (e *engine) play(fields []string) (err error) {
+	if e.lost {
+		return errNoPosition
+	}
+	moves, err := parseMoves(fields)
+	if err != nil {
+		return err
+	}
+	// Only change the position if every move is legal.
+	board, turn := e.board, e.turn
+	for _, move := range moves {
+		if err = board.Apply(move, turn); err != nil {
+			return fmt.Errorf("%v: %w", move, err)
+		}
+		turn = turn.Opponent()
+	}
+	e.board, e.turn = board, turn
+	return nil
+}
+
+// search starts the agent thinking in the background.
+func (e *engine) search(ctx context.Context, fields []string) (err error) {
+	if e.done != nil {
+		select {
+		case <-e.done:
+			e.stopSearch()
+		default:
+			return errAlreadySearching
+		}
+	}
+	if e.lost {
+		return errNoPosition
+	}
+	limits, err := parseGo(fields)
+	if err != nil {
+		return err
+	}
+	ctx, stop := context.WithCancel(ctx)
+	ctx = agent.WithLimits(ctx, limits)
+	ctx = agent.WithInfo(ctx, func(info agent.Info) {
+		e.send("%s", formatInfo(info))
+	})
+	e.stop, e.done = stop, make(chan struct{})
+	go func(board game.Board, turn game.Color, done chan struct{}) {
+		defer close(done)
+		move, err := e.agent.SelectMove(ctx, board, turn)
+		if limits.Infinite {
+			<-ctx.Done()
+		}
+		if err == agent.ErrNoLegalMoves {
+			e.send("bestmove none")
+			return
+		}
+		if err != nil {
+			e.send("info string error: %v", err)
+			e.send("bestmove none")
+			return
+		}
+		e.send("bestmove %v", move)
+	}(e.board, e.turn, e.done)
+	return nil
+}
+
+// stopSearch stops the search, if there is one, and waits for it to send its move.
+func (e *engine) stopSearch() {
+	if e.done == nil {
+		return
+	}
+	e.stop()
+	<-e.done
+	e.stop, e.done = nil, nil
+}
@@ -0,0 +1,161 @@
+// Package protocol runs agents as separate programs, talking over their standard input and output.
+//
+// The client sends one command per line:
+//
+//	fep                          Start talking. The engine answers with "id name <name>", then "fepok".
+//	isready                      The engine answers "readyok".
+//	newgame                      Forget the last game and go back to the starting position.
+//	position start|<position> [moves <move>...]
+//	                             Set up a position, written in the notation of the game package, then play any moves from it.
+//	moves <move>...              Play moves from the current position.
+//	go [movetime <ms>] [clock <ms>] [increment <ms>] [nodes <n>] [depth <n>] [infinite]
+//	                             Search the current position for the player to move. Times are in milliseconds.
+//	stop                         Stop searching, and answer with the best move so far.
+//	quit                         Stop the engine.
+//
+// The engine sends:
+//
+//	id name <name>
+//	fepok
+//	readyok
+//	info [depth <n>] [score <x>] [nodes <n>] [time <ms>] [pv <move>...]
+//	                             Progress while searching.
+//	info string <text>           Anything else, such as "error: <text>" for an error in a command.
+//	                             If a position or moves can't be set up, the engine has no position until the next one is,
+//	                             and answers go with an error, then "bestmove none".
+//	bestmove <move>|none         The move chosen once a search ends, or none if there are no legal moves.
+//	                             An infinite search only sends it after a stop.
+//
+// Unknown commands are ignored, so that the protocol can grow.
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+var (
+	ErrEngineClosed  = errors.New("The engine stopped responding")
+	ErrEngineError   = errors.New("The engine reported an error")
+	ErrEngineTimeout = errors.New("The engine took too long to answer")
+)
+
+func formatGo(limits agent.SearchLimits) string {
+	fields := []string{"go"}
+	if limits.MoveTime > 0 {
+		fields = append(fields, "movetime", strconv.FormatInt(limits.MoveTime.Milliseconds(), 10))
+	}
+	if limits.Clock > 0 {
+		fields = append(fields, "clock", strconv.FormatInt(limits.Clock.Milliseconds(), 10))
+	}
+	if limits.Increment > 0 {
+		fields = append(fields, "increment", strconv.FormatInt(limits.Increment.Milliseconds(), 10))
+	}
+	if limits.Nodes > 0 {
+		fields = append(fields, "nodes", strconv.FormatUint(limits.Nodes, 10))
+	}
+	if limits.Depth > 0 {
+		fields = append(fields, "depth", strconv.Itoa(limits.Depth))
+	}
+	if limits.Infinite {
+		fields = append(fields, "infinite")
+	}
+	return strings.Join(fields, " ")
+}
+
+// parseGo reads the limits from the fields of a go command after "go".
+func parseGo(fields []string) (limits agent.SearchLimits, err error) {
+	for i := 0; i < len(fields); i++ {
+		if fields[i] == "infinite" {
+			limits.Infinite = true
+			continue
+		}
+		if i+1 >= len(fields) {
+			return limits, fmt.Errorf("go %s needs a value", fields[i])
+		}
+		value, err := strconv.ParseUint(fields[i+1], 10, 64)
+		if err != nil {
+			return limits, fmt.Errorf("go %s should be a whole number, got %q", fields[i], fields[i+1])
+		}
+		switch fields[i] {
+		case "movetime":
+			limits.MoveTime = time.Duration(value) * time.Millisecond
+		case "clock":
+			limits.Clock = time.Duration(value) * time.Millisecond
+		case "increment":
+			limits.Increment = time.Duration(value) * time.Millisecond
+		case "nodes":
+			limits.Nodes = value
+		case "depth":
+			limits.Depth = int(value)
+		default:
+			return limits, fmt.Errorf("Unknown go limit %q", fields[i])
+		}
+		i++
+	}
+	return limits, nil
+}
+
+func formatInfo(info agent.Info) string {
+	s := fmt.Sprintf("info depth %d score %s nodes %d time %d", info.Depth, strconv.FormatFloat(info.Score, 'g', -1, 64), info.Nodes, info.Time.Milliseconds())
+	if len(info.PV) > 0 {
+		s += " pv " + formatMoves(info.PV)
+	}
+	return s
+}
+
+// parseInfo reads the fields of an info line after "info". The pv comes last, as it runs to the end of the line.
+func parseInfo(fields []string) (info agent.Info, err error) {
+	for i := 0; i < len(fields); i++ {
+		if fields[i] == "pv" {
+			info.PV, err = parseMoves(fields[i+1:])
+			return info, err
+		}
+		if i+1 >= len(fields) {
+			return info, fmt.Errorf("info %s needs a value", fields[i])
+		}
+		value := fields[i+1]
+		switch fields[i] {
+		case "depth":
+			info.Depth, err = strconv.Atoi(value)
+		case "score":
+			info.Score, err = strconv.ParseFloat(value, 64)
+		case "nodes":
+			info.Nodes, err = strconv.ParseUint(value, 10, 64)
+		case "time":
+			var ms int64
+			ms, err = strconv.ParseInt(value, 10, 64)
+			info.Time = time.Duration(ms) * time.Millisecond
+		}
+		if err != nil {
+			return info, fmt.Errorf("info %s can't be %q", fields[i], value)
+		}
+		i++
+	}
+	return info, nil
+}
+
+func formatMoves(moves []game.Move) string {
+	names := make([]string, len(moves))
+	for i, move := range moves {
+		names[i] = move.String()
+	}
+	return strings.Join(names, " ")
+}
+
+func parseMoves(fields []string) (moves []game.Move, err error) {
+	for _, field := range fields {
+		move, err := game.ParseMove(field)
+		if err != nil {
+			return moves, err
+		}
+		moves = append(moves, move)
+	}
+	return moves, nil
+}
@@ -0,0 +1,256 @@
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// thinker plays the first legal move, reporting the limits it was given as info along the way.
+type thinker struct {
+	mutex  sync.Mutex
+	limits agent.SearchLimits
+}
+
+func (*thinker) Name() string {
+	return "thinker"
+}
+
+func (th *thinker) SelectMove(ctx context.Context, board game.Board, playerColor game.Color) (game.Move, error) {
+	th.mutex.Lock()
+	th.limits = agent.LimitsFrom(ctx)
+	th.mutex.Unlock()
+	moves := board.LegalMoves(playerColor)
+	if len(moves) == 0 {
+		return game.Move{}, agent.ErrNoLegalMoves
+	}
+	agent.ReportInfo(ctx, agent.Info{Depth: 2, Score: -1.5, Nodes: 10, PV: moves[:2]})
+	return moves[0], nil
+}
+
+// connect starts an engine for the agent and a client talking to it.
+func connect(t *testing.T, a agent.Agent) *Client {
+	commands, commandWriter := io.Pipe()
+	answers, answerWriter := io.Pipe()
+	go func() {
+		Serve(context.Background(), a, commands, answerWriter)
+		answerWriter.Close()
+	}()
+	c, err := NewClient(context.Background(), answers, commandWriter)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClient(t *testing.T) {
+	th := &thinker{}
+	c := connect(t, th)
+	if c.Name() != "thinker" {
+		t.Errorf("Expected the engine's name, got %q", c.Name())
+	}
+
+	var infos []agent.Info
+	limits := agent.SearchLimits{Clock: 2 * time.Second, Increment: 100 * time.Millisecond, Depth: 4}
+	ctx := agent.WithInfo(agent.WithLimits(context.Background(), limits), func(info agent.Info) {
+		infos = append(infos, info)
+	})
+	b := game.NewStartingBoard()
+	b.ReservesG = 2
+	move, err := c.SelectMove(ctx, b, game.GREEN)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := b.LegalMoves(game.GREEN)
+	if !move.Equal(expected[0]) {
+		t.Errorf("Expected %v, got %v", expected[0], move)
+	}
+	if th.limits != limits {
+		t.Errorf("Expected the engine to be given %+v, got %+v", limits, th.limits)
+	}
+	if len(infos) != 1 || infos[0].Depth != 2 || infos[0].Score != -1.5 || len(infos[0].PV) != 2 || !infos[0].PV[1].Equal(expected[1]) {
+		t.Errorf("Expected the engine's info to be passed on, got %+v", infos)
+	}
+
+	if _, err := c.SelectMove(context.Background(), game.NewBoard(), game.RED); err != agent.ErrNoLegalMoves {
+		t.Errorf("Expected ErrNoLegalMoves, got %v", err)
+	}
+}
+
+func TestClientStop(t *testing.T) {
+	c := connect(t, agent.NewRandom(1))
+	ctx, stop := context.WithCancel(agent.WithLimits(context.Background(), agent.SearchLimits{Infinite: true}))
+	time.AfterFunc(20*time.Millisecond, stop)
+	b := game.NewStartingBoard()
+	move, err := c.SelectMove(ctx, b, game.RED)
+	if err != nil || b.Apply(move, game.RED) != nil {
+		t.Errorf("Expected a legal move once stopped, got %v, %v", move, err)
+	}
+}
+
+func TestServe(t *testing.T) {
+	commands := strings.Join([]string{
+		"fep",
+		"isready",
+		"position start moves b2-c2 b3-b2",
+		"moves b2-b1",
+		"position xx4xx/xrg4x/8/8/8/8/x6x/xx4xx r 0 0",
+		"sing a song",
+		"go depth 1",
+		"quit",
+	}, "\n")
+	var out bytes.Buffer
+	if err := Serve(context.Background(), agent.NewRandom(1), strings.NewReader(commands), &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	expected := []string{"id name random", "fepok", "readyok", "info string error: b2-b1: You cannot move your opponent's piece"}
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 lines, got %q", lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], lines[i])
+		}
+	}
+	if !strings.HasPrefix(lines[4], "bestmove b2-") {
+		t.Errorf("Expected a move for the only red piece, got %q", lines[4])
+	}
+}
+
+func TestServeLostPosition(t *testing.T) {
+	commands := strings.Join([]string{
+		"position start moves b2-b1",
+		"go depth 1",
+		"moves b2-c2",
+		"go depth 1",
+		"position start",
+		"go depth 1",
+		"quit",
+	}, "\n")
+	var out bytes.Buffer
+	if err := Serve(context.Background(), agent.NewRandom(1), strings.NewReader(commands), &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("Expected 7 lines, got %q", lines)
+	}
+	// Neither search should be of the position from before the one that failed.
+	for _, i := range []int{1, 4} {
+		if !strings.HasPrefix(lines[i], "info string error: ") || lines[i+1] != "bestmove none" {
+			t.Errorf("Expected an error and no move, got %q", lines[i:i+2])
+		}
+	}
+	if !strings.HasPrefix(lines[6], "bestmove ") || lines[6] == "bestmove none" {
+		t.Errorf("Expected a move once a position is set up again, got %q", lines[6])
+	}
+}
+
+func TestClientEngineError(t *testing.T) {
+	commands, commandWriter := io.Pipe()
+	answers, answerWriter := io.Pipe()
+	// An engine that won't take any position, but searches its own anyway.
+	go func() {
+		scanner := bufio.NewScanner(commands)
+		for scanner.Scan() {
+			switch strings.Fields(scanner.Text())[0] {
+			case "fep":
+				io.WriteString(answerWriter, "fepok\n")
+			case "position":
+				io.WriteString(answerWriter, "info string error: Invalid position\n")
+			case "go":
+				io.WriteString(answerWriter, "bestmove b2-b3\n")
+			}
+		}
+		answerWriter.Close()
+	}()
+	c, err := NewClient(context.Background(), answers, commandWriter)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { commandWriter.Close() })
+	if _, err := c.SelectMove(context.Background(), game.NewStartingBoard(), game.RED); !errors.Is(err, ErrEngineError) {
+		t.Errorf("Expected ErrEngineError, got %v", err)
+	}
+}
+
+func TestClientHandshakeTimeout(t *testing.T) {
+	commands, commandWriter := io.Pipe()
+	answers, answerWriter := io.Pipe()
+	// An engine that listens, but never says it is ready.
+	stopped := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, commands)
+		answerWriter.Close()
+		close(stopped)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := NewClient(ctx, answers, commandWriter); !errors.Is(err, ErrEngineTimeout) {
+		t.Errorf("Expected ErrEngineTimeout, got %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the engine to be told to stop once the client gave up")
+	}
+}
+
+func TestClientClose(t *testing.T) {
+	commands, commandWriter := io.Pipe()
+	answers, answerWriter := io.Pipe()
+	// An engine with more to say after quit, which only stops once its input ends.
+	go func() {
+		scanner := bufio.NewScanner(commands)
+		for scanner.Scan() {
+			switch scanner.Text() {
+			case "fep":
+				io.WriteString(answerWriter, "fepok\n")
+			case "quit":
+				io.WriteString(answerWriter, "info string bye\ninfo string really\n")
+			}
+		}
+		answerWriter.Close()
+	}()
+	c, err := NewClient(context.Background(), answers, commandWriter)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	closed := make(chan error, 1)
+	go func() {
+		closed <- c.Close()
+	}()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected Close to return once the engine stopped")
+	}
+}
+
+func TestLimitsRoundTrip(t *testing.T) {
+	for _, limits := range []agent.SearchLimits{{}, {MoveTime: 250 * time.Millisecond}, {Clock: time.Minute, Increment: time.Second, Nodes: 5000, Depth: 7}, {Infinite: true}} {
+		parsed, err := parseGo(strings.Fields(formatGo(limits))[1:])
+		if err != nil || parsed != limits {
+			t.Errorf("Expected %+v, got %+v, %v", limits, parsed, err)
+		}
+	}
+	for _, invalid := range []string{"movetime", "nodes -1", "depth x", "ponder 3"} {
+		if _, err := parseGo(strings.Fields(invalid)); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
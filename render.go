@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/headblockhead/focus-ai/render"
+)
+
+// renderPosition draws a position to a PNG, without needing a display.
+func renderPosition(args []string) (err error) {
+	options := render.DefaultOptions()
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	position := flags.String("position", "start", "Position to draw")
+	moves := flags.String("moves", "", "Moves to play from the position first, separated by spaces")
+	out := flags.String("out", "position.png", "File to write the PNG to")
+	flags.IntVar(&options.TileSize, "tile", options.TileSize, "Width of each tile in pixels")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	board, turn, err := game.ParsePosition(*position)
+	if err != nil {
+		return err
+	}
+	for _, notation := range strings.Fields(*moves) {
+		move, err := game.ParseMove(notation)
+		if err != nil {
+			return err
+		}
+		if err = board.Apply(move, turn); err != nil {
+			return fmt.Errorf("%v: %w", move, err)
+		}
+		turn = turn.Opponent()
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err = render.PNG(f, board, turn, options); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,83 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// An Animation is how a whole game is drawn, a frame for every move.
+type Animation struct {
+	Options
+	// Delay is how long each frame is shown for. The last is shown for longer, to see how the game ended before it starts again.
+	Delay time.Duration
+	// Caption, if set, gives what to write under the board ply moves into the game, in place of the move just played.
+	Caption func(ply int) string
+}
+
+func DefaultAnimation() Animation {
+	return Animation{Options: DefaultOptions(), Delay: 800 * time.Millisecond}
+}
+
+// palette is every colour a board is drawn in, so frames need no dithering.
+var palette = color.Palette{background, lightTile, darkTile, textColor, moveColor, PieceColors[game.RED], PieceColors[game.GREEN], edge}
+
+// Caption says what happened on the move ply moves into the game, and how the game ended once it has.
+func Caption(record game.Record, ply int) string {
+	if ply == 0 {
+		if record.Red == "" && record.Green == "" {
+			return "Start"
+		}
+		return fmt.Sprintf("Start, %s v %s", record.Red, record.Green)
+	}
+	caption := fmt.Sprintf("%d. %v played %v", ply, record.TurnAt(ply-1), record.Moves[ply-1])
+	if ply == len(record.Moves) && record.Result != game.UNDECIDED {
+		caption += fmt.Sprintf(", %v", record.Result)
+		if record.Reason != "" {
+			caption += fmt.Sprintf(" (%s)", record.Reason)
+		}
+	}
+	return caption
+}
+
+// GIF draws the position before every move of a game and after the last, writing them as a looping animated GIF.
+func GIF(w io.Writer, record game.Record, animation Animation) (err error) {
+	boards, err := record.Positions()
+	if err != nil {
+		return err
+	}
+	caption := animation.Caption
+	if caption == nil {
+		caption = func(ply int) string {
+			return Caption(record, ply)
+		}
+	}
+	// GIF delays are in hundredths of a second.
+	delay := int(animation.Delay / (10 * time.Millisecond))
+	out := &gif.GIF{}
+	for ply, board := range boards {
+		options := animation.Options
+		// Every frame has a caption, even if it is blank, so they are all the same size.
+		options.Caption = caption(ply)
+		if options.Caption == "" {
+			options.Caption = " "
+		}
+		options.Move = nil
+		if ply > 0 {
+			options.Move = &record.Moves[ply-1]
+		}
+		img := Image(board, record.TurnAt(ply), options)
+		frame := image.NewPaletted(img.Bounds(), palette)
+		draw.Draw(frame, frame.Bounds(), img, image.Point{}, draw.Src)
+		out.Image = append(out.Image, frame)
+		out.Delay = append(out.Delay, delay)
+	}
+	out.Delay[len(out.Delay)-1] = 3 * delay
+	return gif.EncodeAll(w, out)
+}
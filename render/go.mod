@@ -0,0 +1,10 @@
+module github.com/headblockhead/focus-ai/render
+
+require (
+	github.com/headblockhead/focus-ai/game v0.0.0
+	golang.org/x/image v0.6.0
+)
+
+replace github.com/headblockhead/focus-ai/game v0.0.0 => ../game
+
+go 1.20
@@ -0,0 +1,165 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/headblockhead/focus-ai/game"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Options are how a position is drawn.
+type Options struct {
+	// TileSize is how wide each tile is in pixels.
+	TileSize int
+	// Move, if set, is outlined on the board, such as the move that was just played.
+	Move *game.Move
+	// Caption, if set, is written underneath.
+	Caption string
+}
+
+func DefaultOptions() Options {
+	return Options{TileSize: 48}
+}
+
+var (
+	background = color.RGBA{R: 30, G: 30, B: 36, A: 255}
+	lightTile  = color.RGBA{R: 222, G: 200, B: 160, A: 255}
+	darkTile   = color.RGBA{R: 196, G: 170, B: 126, A: 255}
+	textColor  = color.RGBA{R: 235, G: 235, B: 235, A: 255}
+	moveColor  = color.RGBA{R: 250, G: 210, B: 40, A: 255}
+	// PieceColors are the colours pieces are drawn in, by colour.
+	PieceColors = [2]color.RGBA{
+		game.RED:   {R: 205, G: 45, B: 45, A: 255},
+		game.GREEN: {R: 45, G: 160, B: 70, A: 255},
+	}
+	edge = color.RGBA{R: 20, G: 20, B: 20, A: 255}
+)
+
+// The basic font is 7 by 13 pixels, and lines of it are spaced lineHeight apart.
+const (
+	charWidth  = 7
+	lineHeight = 16
+)
+
+// Image draws a position from above, with row 8 at the top like the tiles are named, the columns and rows labelled around the edge, and the reserves and whose turn it is underneath.
+// Each stack is drawn side on within its tile, bottom piece lowest, with its height in the corner.
+func Image(b game.Board, turn game.Color, options Options) *image.RGBA {
+	tile := options.TileSize
+	if tile <= 0 {
+		tile = DefaultOptions().TileSize
+	}
+	margin := lineHeight + 8
+	width := 2*margin + 8*tile
+	height := 2*margin + 8*tile + 2*lineHeight + 8
+	if options.Caption != "" {
+		height += lineHeight
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			drawTile(img, &b.Tiles[x][y], image.Rect(margin+x*tile, margin+(7-y)*tile, margin+(x+1)*tile, margin+(8-y)*tile), (x+y)%2 == 0)
+		}
+	}
+	if move := options.Move; move != nil {
+		// Where a tile is, allowing for moves off the board.
+		at := func(x int, y int) image.Rectangle {
+			return image.Rect(margin+x*tile, margin+(7-y)*tile, margin+(x+1)*tile, margin+(8-y)*tile).Intersect(img.Bounds())
+		}
+		if !move.FromReserve {
+			outline(img, at(move.X, move.Y), 2, moveColor)
+		}
+		outline(img, at(move.Destination()), 3, moveColor)
+	}
+	for i := 0; i < 8; i++ {
+		column := string(rune('a' + i))
+		row := fmt.Sprint(i + 1)
+		middle := margin + i*tile + tile/2
+		centred(img, column, middle, margin-6, textColor)
+		centred(img, column, middle, margin+8*tile+lineHeight-2, textColor)
+		centred(img, row, margin/2, margin+(7-i)*tile+tile/2+5, textColor)
+		centred(img, row, width-margin/2, margin+(7-i)*tile+tile/2+5, textColor)
+	}
+
+	y := 2*margin + 8*tile + 4
+	text(img, fmt.Sprintf("%v to move", turn), margin, y, textColor)
+	y += lineHeight
+	x := text(img, "Reserves:", margin, y, textColor) + charWidth
+	for _, color := range []game.Color{game.RED, game.GREEN} {
+		reserves := *b.GetReserves(color)
+		x = text(img, fmt.Sprintf("%v %d", color, reserves), x, y, textColor) + 4
+		// A square for each piece, as many as fit.
+		for i := 0; i < reserves && x+10 < width/2*(int(color)+1); i++ {
+			square(img, image.Rect(x, y-10, x+8, y-2), PieceColors[color])
+			x += 10
+		}
+		x = width/2 + 4
+	}
+	if options.Caption != "" {
+		text(img, options.Caption, margin, y+lineHeight, textColor)
+	}
+	return img
+}
+
+// drawTile draws a tile and the stack on it.
+func drawTile(img *image.RGBA, tile *game.Tile, r image.Rectangle, light bool) {
+	if !tile.Useable() {
+		return
+	}
+	c := darkTile
+	if light {
+		c = lightTile
+	}
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+	height := tile.Height()
+	if height == 0 {
+		return
+	}
+	pad := r.Dx() / 8
+	inner := r.Inset(pad)
+	band := inner.Dy() / len(tile.Pieces)
+	for k := 0; k < height; k++ {
+		bottom := inner.Max.Y - k*band
+		square(img, image.Rect(inner.Min.X, bottom-band, inner.Max.X, bottom), PieceColors[tile.Pieces[k].Color])
+	}
+	text(img, fmt.Sprint(height), r.Min.X+2, r.Min.Y+12, edge)
+}
+
+// square fills a rectangle with a darker edge, so pieces next to each other can be told apart.
+func square(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, image.NewUniform(edge), image.Point{}, draw.Src)
+	draw.Draw(img, r.Inset(1), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// outline draws a border of thickness inside a rectangle.
+func outline(img *image.RGBA, r image.Rectangle, thickness int, c color.RGBA) {
+	u := image.NewUniform(c)
+	draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+thickness), u, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(r.Min.X, r.Max.Y-thickness, r.Max.X, r.Max.Y), u, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+thickness, r.Max.Y), u, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(r.Max.X-thickness, r.Min.Y, r.Max.X, r.Max.Y), u, image.Point{}, draw.Src)
+}
+
+// text writes s with its baseline at y, returning where it ends.
+func text(img *image.RGBA, s string, x int, y int, c color.Color) int {
+	d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: basicfont.Face7x13, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+	return d.Dot.X.Round()
+}
+
+func centred(img *image.RGBA, s string, x int, y int, c color.Color) {
+	text(img, s, x-len(s)*charWidth/2, y, c)
+}
+
+// PNG writes the image of a position as a PNG.
+func PNG(w io.Writer, b game.Board, turn game.Color, options Options) error {
+	return png.Encode(w, Image(b, turn, options))
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+func TestImage(t *testing.T) {
+	b := game.NewStartingBoard()
+	b.ReservesR = 2
+	options := Options{TileSize: 40}
+	img := Image(b, game.RED, options)
+
+	// 8 tiles, with room for the labels around them and the reserves underneath.
+	if img.Bounds().Dx() <= 8*40 || img.Bounds().Dy() <= 8*40 {
+		t.Fatalf("Expected room for 8 tiles of 40 pixels, got %v", img.Bounds())
+	}
+	margin := (img.Bounds().Dx() - 8*40) / 2
+	// The middle of the tile at x, y, with row 8 at the top.
+	at := func(x int, y int) color.Color {
+		return img.At(margin+x*40+20, margin+(7-y)*40+30)
+	}
+	if c := at(1, 1); c != PieceColors[game.RED] {
+		t.Errorf("Expected b2 to have a red piece, got %v", c)
+	}
+	if c := at(3, 1); c != PieceColors[game.GREEN] {
+		t.Errorf("Expected d2 to have a green piece, got %v", c)
+	}
+	if c := at(0, 0); c != background {
+		t.Errorf("Expected the unusable a1 to be left as background, got %v", c)
+	}
+
+	var buf bytes.Buffer
+	if err := PNG(&buf, b, game.RED, options); err != nil {
+		t.Fatalf("Expected the PNG to encode, got %v", err)
+	}
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Expected the PNG to decode, got %v", err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Errorf("Expected the PNG to be %v, got %v", img.Bounds(), decoded.Bounds())
+	}
+}
+
+func TestGIF(t *testing.T) {
+	record := game.Record{Red: "a", Green: "b", Start: game.NewStartingBoard(), Turn: game.RED}
+	for _, notation := range []string{"b2-b3", "d2-d3"} {
+		move, err := game.ParseMove(notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		record.Moves = append(record.Moves, move)
+	}
+	record.Result = game.GREEN_WON
+
+	var buf bytes.Buffer
+	if err := GIF(&buf, record, DefaultAnimation()); err != nil {
+		t.Fatalf("Expected the GIF to encode, got %v", err)
+	}
+	decoded, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("Expected the GIF to decode, got %v", err)
+	}
+	if len(decoded.Image) != 3 {
+		t.Fatalf("Expected a frame for the start and each of the 2 moves, got %d", len(decoded.Image))
+	}
+	if decoded.Delay[0] != 80 || decoded.Delay[2] != 240 {
+		t.Errorf("Expected frames of 80 and the last of 240 hundredths, got %v", decoded.Delay)
+	}
+	if caption := Caption(record, 2); caption != "2. GREEN played d2-d3, GREEN_WON" {
+		t.Errorf("Expected the last caption to give the result, got %q", caption)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// replay opens a recorded game in the visualizer to step through.
+func replay(args []string) (err error) {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	records := flags.String("records", "", "File of game records to replay from")
+	index := flags.Int("game", 0, "Which game in the file to replay, counting from 0, or from the end if negative")
+	interval := flags.Duration("interval", time.Second, "Time between moves when playing through the game")
+	play := flags.Bool("play", false, "Start playing through the game straight away")
+	options := windowFlags(flags)
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *records == "" {
+		return fmt.Errorf("-records is required")
+	}
+	f, err := os.Open(*records)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	games, err := game.ReadRecords(f)
+	if err != nil {
+		return err
+	}
+	i := *index
+	if i < 0 {
+		i += len(games)
+	}
+	if i < 0 || i >= len(games) {
+		return fmt.Errorf("%s has %d games, so there is no game %d", *records, len(games), *index)
+	}
+	return openReplay(games[i], *interval, *play, *options)
+}
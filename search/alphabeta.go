@@ -0,0 +1,324 @@
+package search
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/eval"
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/headblockhead/focus-ai/tablebase"
+)
+
+// WIN is the score of a won position. Wins found sooner score higher, so the search takes the quickest one.
+const WIN = 1000000
+
+func isWin(score float64) bool {
+	return math.Abs(score) > WIN-1000
+}
+
+// Scores of wins are stored relative to the position they are found in, rather than the root of the search, so they stay right when reached by a different path.
+func toTable(score float64, ply int) float32 {
+	if score > WIN-1000 {
+		score += float64(ply)
+	} else if score < -(WIN - 1000) {
+		score -= float64(ply)
+	}
+	return float32(score)
+}
+
+func fromTable(score float32, ply int) float64 {
+	s := float64(score)
+	if s > WIN-1000 {
+		s -= float64(ply)
+	} else if s < -(WIN - 1000) {
+		s += float64(ply)
+	}
+	return s
+}
+
+// AlphaBeta searches with negamax alpha-beta pruning and iterative deepening, sharing what it finds through a transposition table.
+// It searches to Depth unless the context carries other agent.SearchLimits.
+// With more than one thread it uses Lazy SMP: helper threads search the same position at staggered depths with shuffled move orders, filling the shared table with results the main thread can reuse.
+type AlphaBeta struct {
+	Weights eval.Weights
+	Depth   int
+	Table   *Table
+	Threads int
+	// Deterministic runs the helper threads one after another before the main thread, so the same search always gives the same result. It is much slower, and meant for tests.
+	Deterministic bool
+	// Tablebase, if set, gives exact scores for positions with few enough pieces.
+	Tablebase   *tablebase.Tablebase
+	TimeManager agent.TimeManager
+}
+
+// MAX_DEPTH is as deep as a search without a depth limit goes.
+const MAX_DEPTH = 64
+
+func NewAlphaBeta(weights eval.Weights, depth int, table *Table) *AlphaBeta {
+	return &AlphaBeta{Weights: weights, Depth: depth, Table: table, Threads: 1, TimeManager: agent.DefaultTimeManager()}
+}
+
+func (ab *AlphaBeta) Name() string {
+	return "alphabeta"
+}
+
+// Result is what a search found.
+type Result struct {
+	Move  game.Move
+	Score float64
+	Depth int
+	Nodes uint64
+	// PV is the line of play the search expects, starting with Move.
+	PV []game.Move
+	// Policy is the share of the search each legal move got, in the order of Board.LegalMoves. Only MCTS sets it.
+	Policy []float64
+}
+
+func (ab *AlphaBeta) SelectMove(ctx context.Context, board game.Board, playerColor game.Color) (game.Move, error) {
+	result, err := ab.Search(ctx, board, playerColor)
+	return result.Move, err
+}
+
+// Search deepens one ply at a time until it reaches the depth limit, or runs out of time or nodes.
+// If it is stopped, or ctx is cancelled, it returns the result of the deepest search that finished.
+func (ab *AlphaBeta) Search(ctx context.Context, board game.Board, playerColor game.Color) (result Result, err error) {
+	start := time.Now()
+	moves := board.LegalMoves(playerColor)
+	if len(moves) == 0 {
+		return result, agent.ErrNoLegalMoves
+	}
+	result.Move = moves[0]
+	ab.Table.NewSearch()
+
+	limits := agent.LimitsFrom(ctx)
+	if limits.IsZero() {
+		limits.Depth = ab.Depth
+	}
+	maxDepth := limits.Depth
+	if maxDepth <= 0 || limits.Infinite {
+		maxDepth = MAX_DEPTH
+	}
+	var nodeLimit uint64
+	if !limits.Infinite {
+		nodeLimit = limits.Nodes
+	}
+	budget := ab.TimeManager.Budget(limits, &board)
+	ctx, cancel := budget.Context(ctx)
+	defer cancel()
+	// Every thread counts towards the node limit.
+	var spent atomic.Uint64
+
+	helperCtx, stopHelpers := context.WithCancel(ctx)
+	defer stopHelpers()
+	var wg sync.WaitGroup
+	var helperNodes atomic.Uint64
+	for thread := 1; thread < ab.Threads; thread++ {
+		helper := &searcher{ctx: helperCtx, weights: ab.Weights, table: ab.Table, tablebase: ab.Tablebase, nodeLimit: nodeLimit, spent: &spent, random: rand.New(rand.NewSource(int64(thread)))}
+		// Half the helpers start a ply deeper, so the threads aren't all working on the same depth.
+		startDepth := 1 + thread%2
+		if ab.Deterministic {
+			helper.deepen(&board, playerColor, startDepth, maxDepth, &Result{})
+			helperNodes.Add(helper.nodes)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			helper.deepen(&board, playerColor, startDepth, maxDepth, &Result{})
+			helperNodes.Add(helper.nodes)
+		}()
+	}
+
+	primary := &searcher{ctx: ctx, weights: ab.Weights, table: ab.Table, tablebase: ab.Tablebase, nodeLimit: nodeLimit, spent: &spent, start: start, soft: budget.Soft, report: true}
+	primary.deepen(&board, playerColor, 1, maxDepth, &result)
+	stopHelpers()
+	wg.Wait()
+	result.Nodes = primary.nodes + helperNodes.Load()
+	return result, nil
+}
+
+type searcher struct {
+	ctx       context.Context
+	weights   eval.Weights
+	table     *Table
+	tablebase *tablebase.Tablebase
+	// random, if set, shuffles the moves searched after the table's best move.
+	random *rand.Rand
+	nodes  uint64
+	// nodeLimit, if set, stops the search once the searchers have spent that many nodes between them.
+	nodeLimit uint64
+	spent     *atomic.Uint64
+	// soft, if set, stops the search starting a new depth once half of it has passed since start.
+	start time.Time
+	soft  time.Duration
+	// report sends agent.Info for every depth that finishes.
+	report  bool
+	stopped bool
+	// rootMove is the best move found at ply 0 by the last search.
+	rootMove game.Move
+}
+
+// deepen searches from startDepth to maxDepth, updating result after each depth that finishes.
+func (s *searcher) deepen(board *game.Board, turn game.Color, startDepth int, maxDepth int, result *Result) {
+	for depth := startDepth; depth <= maxDepth; depth++ {
+		score := s.negamax(board, turn, depth, math.Inf(-1), math.Inf(1), 0)
+		if s.stopped {
+			return
+		}
+		result.Move = s.rootMove
+		result.Score = score
+		result.Depth = depth
+		result.PV = s.principalVariation(*board, turn, depth)
+		if s.report {
+			agent.ReportInfo(s.ctx, agent.Info{Depth: depth, Score: score, Nodes: s.nodes, Time: time.Since(s.start), PV: result.PV})
+		}
+		if isWin(score) {
+			return
+		}
+		// The next depth would take longer than all of the ones before it put together.
+		if s.soft > 0 && time.Since(s.start) > s.soft/2 {
+			return
+		}
+	}
+}
+
+func (s *searcher) negamax(board *game.Board, turn game.Color, depth int, alpha float64, beta float64, ply int) float64 {
+	s.nodes++
+	if s.nodes%1024 == 0 && s.ctx.Err() != nil {
+		s.stopped = true
+	}
+	if s.nodeLimit > 0 && s.spent.Add(1) > s.nodeLimit {
+		s.stopped = true
+	}
+	if s.stopped {
+		return 0
+	}
+	if s.tablebase != nil && ply > 0 {
+		if v, ok := s.tablebase.Probe(board, turn); ok {
+			return tablebaseScore(v, ply)
+		}
+	}
+	moves := board.LegalMoves(turn)
+	if len(moves) == 0 {
+		return -(WIN - float64(ply))
+	}
+	if depth == 0 {
+		return s.weights.Evaluate(board, turn)
+	}
+
+	hash := board.Hash(turn)
+	originalAlpha := alpha
+	entry, found := s.table.Probe(hash)
+	if found && int(entry.Depth) >= depth && ply > 0 {
+		score := fromTable(entry.Score, ply)
+		switch entry.Bound {
+		case EXACT:
+			return score
+		case LOWER:
+			alpha = math.Max(alpha, score)
+		case UPPER:
+			beta = math.Min(beta, score)
+		}
+		if alpha >= beta {
+			return score
+		}
+	}
+	if s.random != nil {
+		s.random.Shuffle(len(moves), func(i, j int) {
+			moves[i], moves[j] = moves[j], moves[i]
+		})
+	}
+	if found {
+		orderFirst(moves, entry.Move)
+	}
+
+	best := math.Inf(-1)
+	var bestMove game.Move
+	for _, move := range moves {
+		child := *board
+		child.Apply(move, turn)
+		score := -s.negamax(&child, turn.Opponent(), depth-1, -beta, -alpha, ply+1)
+		if s.stopped {
+			return 0
+		}
+		if score > best {
+			best, bestMove = score, move
+		}
+		alpha = math.Max(alpha, score)
+		if alpha >= beta {
+			break
+		}
+	}
+
+	if ply == 0 {
+		s.rootMove = bestMove
+	}
+	bound := EXACT
+	if best <= originalAlpha {
+		bound = UPPER
+	} else if best >= beta {
+		bound = LOWER
+	}
+	s.table.Store(Entry{Hash: hash, Score: toTable(best, ply), Move: Pack(bestMove), Depth: int8(depth), Bound: bound})
+	return best
+}
+
+// principalVariation follows the best moves stored in the table from the root move, as far as depth.
+func (s *searcher) principalVariation(board game.Board, turn game.Color, depth int) (pv []game.Move) {
+	move := s.rootMove
+	for {
+		pv = append(pv, move)
+		board.Apply(move, turn)
+		turn = turn.Opponent()
+		if len(pv) >= depth {
+			return pv
+		}
+		entry, ok := s.table.Probe(board.Hash(turn))
+		if !ok {
+			return pv
+		}
+		next, ok := entry.Move.Unpack()
+		if !ok || !isLegal(&board, turn, next) {
+			return pv
+		}
+		move = next
+	}
+}
+
+func isLegal(board *game.Board, turn game.Color, move game.Move) bool {
+	for _, legal := range board.LegalMoves(turn) {
+		if legal.Equal(move) {
+			return true
+		}
+	}
+	return false
+}
+
+// tablebaseScore scores a tablebase value the same way the search scores the wins and losses it finds, by the ply the game ends on.
+func tablebaseScore(v tablebase.Value, ply int) float64 {
+	switch v.Outcome {
+	case tablebase.WIN:
+		return WIN - float64(ply+v.Distance)
+	case tablebase.LOSS:
+		return -(WIN - float64(ply+v.Distance))
+	}
+	return 0
+}
+
+// orderFirst moves the packed move to the front, so it is searched first.
+func orderFirst(moves []game.Move, first PackedMove) {
+	if first == 0 {
+		return
+	}
+	for i := range moves {
+		if Pack(moves[i]) == first {
+			moves[0], moves[i] = moves[i], moves[0]
+			return
+		}
+	}
+}
@@ -0,0 +1,18 @@
+module github.com/headblockhead/focus-ai/search
+
+require (
+	github.com/headblockhead/focus-ai/agent v0.0.0
+	github.com/headblockhead/focus-ai/eval v0.0.0
+	github.com/headblockhead/focus-ai/game v0.0.0
+	github.com/headblockhead/focus-ai/tablebase v0.0.0
+)
+
+replace github.com/headblockhead/focus-ai/game v0.0.0 => ../game
+
+replace github.com/headblockhead/focus-ai/eval v0.0.0 => ../eval
+
+replace github.com/headblockhead/focus-ai/agent v0.0.0 => ../agent
+
+replace github.com/headblockhead/focus-ai/tablebase v0.0.0 => ../tablebase
+
+go 1.20
@@ -0,0 +1,345 @@
+package search
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/eval"
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/headblockhead/focus-ai/tablebase"
+)
+
+type Parallelism int
+
+const (
+	// Threads share one tree, and virtual losses on the path each thread is exploring steer the others elsewhere.
+	TREE Parallelism = iota
+	// Every thread grows its own tree, and their visit counts are added up at the end.
+	ROOT
+)
+
+// MCTS is a Monte Carlo tree search that scores new positions with the evaluation function instead of playing them out.
+// It runs for Iterations unless the context carries other agent.SearchLimits, in which case it counts the iterations as nodes. It has no use for a depth limit.
+type MCTS struct {
+	Weights eval.Weights
+	// Scale turns an evaluation into a win probability, sigmoid(Scale * evaluation).
+	Scale       float64
+	Iterations  int
+	Exploration float64
+	Threads     int
+	Parallelism Parallelism
+	// VirtualLoss is the number of lost visits added to a node while a thread is exploring below it.
+	VirtualLoss int
+	// Deterministic makes a search always give the same result by interleaving the threads' work in a fixed order on one goroutine. It is meant for tests.
+	Deterministic bool
+	Seed          int64
+	// Table, if set, caches the evaluations of new positions. Don't share a table between MCTS and alpha-beta, which store different kinds of score.
+	Table *Table
+	// Tablebase, if set, replaces the evaluation of positions with few enough pieces with their exact result.
+	Tablebase   *tablebase.Tablebase
+	TimeManager agent.TimeManager
+}
+
+func NewMCTS(weights eval.Weights, iterations int) *MCTS {
+	return &MCTS{
+		Weights:     weights,
+		Scale:       0.5,
+		Iterations:  iterations,
+		Exploration: 1.4,
+		Threads:     1,
+		VirtualLoss: 1,
+		Seed:        1,
+		TimeManager: agent.DefaultTimeManager(),
+	}
+}
+
+func (m *MCTS) Name() string {
+	return "mcts"
+}
+
+func (m *MCTS) SelectMove(ctx context.Context, board game.Board, playerColor game.Color) (game.Move, error) {
+	result, err := m.Search(ctx, board, playerColor)
+	return result.Move, err
+}
+
+// valueUnit is the fixed point scale node values are stored in, so that they can be updated atomically.
+const valueUnit = 1 << 20
+
+type node struct {
+	move game.Move
+	// mutex is held while the node is expanded.
+	mutex    sync.Mutex
+	expanded atomic.Bool
+	children []*node
+	terminal bool
+	visits   atomic.Int64
+	// value is the total of the results for the player who moved into this node, in valueUnits.
+	value   atomic.Int64
+	virtual atomic.Int64
+}
+
+// Search runs iterations until it reaches its limits and returns the most visited move. Result.Score is the win probability for playerColor.
+// If ctx is cancelled, it returns the best move found so far.
+func (m *MCTS) Search(ctx context.Context, board game.Board, playerColor game.Color) (result Result, err error) {
+	start := time.Now()
+	moves := board.LegalMoves(playerColor)
+	if len(moves) == 0 {
+		return result, agent.ErrNoLegalMoves
+	}
+	threads := m.Threads
+	if threads < 1 {
+		threads = 1
+	}
+
+	limits := agent.LimitsFrom(ctx)
+	maxIterations := math.MaxInt
+	if !limits.Infinite && limits.Nodes > 0 {
+		maxIterations = int(limits.Nodes)
+	} else if !limits.Infinite && !limits.Timed() {
+		maxIterations = m.Iterations
+	}
+	budget := m.TimeManager.Budget(limits, &board)
+	// Every iteration leaves the tree ready to answer, so there is nothing to finish after the soft limit.
+	budget.Hard = budget.Soft
+	ctx, cancel := budget.Context(ctx)
+	defer cancel()
+
+	var roots []*node
+	var iterations atomic.Int64
+	if m.Parallelism == ROOT {
+		perThread := maxIterations / threads
+		if maxIterations%threads != 0 {
+			perThread++
+		}
+		roots = make([]*node, threads)
+		var wg sync.WaitGroup
+		for thread := 0; thread < threads; thread++ {
+			roots[thread] = &node{}
+			random := rand.New(rand.NewSource(m.Seed + int64(thread)))
+			if m.Deterministic {
+				iterations.Add(int64(m.grow(ctx, roots[thread], board, playerColor, perThread, 1, random)))
+				continue
+			}
+			wg.Add(1)
+			go func(root *node) {
+				defer wg.Done()
+				iterations.Add(int64(m.grow(ctx, root, board, playerColor, perThread, 1, random)))
+			}(roots[thread])
+		}
+		wg.Wait()
+	} else {
+		root := &node{}
+		roots = []*node{root}
+		if m.Deterministic {
+			iterations.Add(int64(m.grow(ctx, root, board, playerColor, maxIterations, threads, rand.New(rand.NewSource(m.Seed)))))
+		} else {
+			var remaining atomic.Int64
+			remaining.Store(int64(maxIterations))
+			var wg sync.WaitGroup
+			for thread := 0; thread < threads; thread++ {
+				wg.Add(1)
+				go func(random *rand.Rand) {
+					defer wg.Done()
+					for remaining.Add(-1) >= 0 && ctx.Err() == nil {
+						path, leaf, turn := m.descend(root, board, playerColor, random)
+						m.backup(path, m.evaluate(&leaf, turn))
+						iterations.Add(1)
+					}
+				}(rand.New(rand.NewSource(m.Seed + int64(thread))))
+			}
+			wg.Wait()
+		}
+	}
+
+	// Add up the visits to each move over every tree.
+	visits := map[PackedMove]int64{}
+	values := map[PackedMove]int64{}
+	for _, root := range roots {
+		for _, child := range root.children {
+			visits[Pack(child.move)] += child.visits.Load()
+			values[Pack(child.move)] += child.value.Load()
+		}
+	}
+	result.Move = moves[0]
+	bestVisits := int64(-1)
+	result.Policy = make([]float64, len(moves))
+	total := int64(0)
+	for _, v := range visits {
+		total += v
+	}
+	for i, move := range moves {
+		packed := Pack(move)
+		// With nothing searched, every move is as likely as the others.
+		result.Policy[i] = 1 / float64(len(moves))
+		if total > 0 {
+			result.Policy[i] = float64(visits[packed]) / float64(total)
+		}
+		if visits[packed] > bestVisits {
+			result.Move, bestVisits = move, visits[packed]
+			result.Score = 0.5
+			if bestVisits > 0 {
+				result.Score = float64(values[packed]) / valueUnit / float64(bestVisits)
+			}
+		}
+	}
+	result.Nodes = uint64(iterations.Load())
+	result.PV = principalVariation(roots[0], result.Move)
+	result.Depth = len(result.PV)
+	agent.ReportInfo(ctx, agent.Info{Depth: result.Depth, Score: result.Score, Nodes: result.Nodes, Time: time.Since(start), PV: result.PV})
+	return result, nil
+}
+
+// principalVariation starts with the move played and follows the most visited children of the root's tree after it.
+func principalVariation(root *node, move game.Move) (pv []game.Move) {
+	pv = append(pv, move)
+	var n *node
+	for _, child := range root.children {
+		if child.move.Equal(move) {
+			n = child
+		}
+	}
+	for n != nil && n.expanded.Load() {
+		var best *node
+		for _, child := range n.children {
+			if child.visits.Load() > 0 && (best == nil || child.visits.Load() > best.visits.Load()) {
+				best = child
+			}
+		}
+		if best == nil {
+			break
+		}
+		pv = append(pv, best.move)
+		n = best
+	}
+	return pv
+}
+
+// grow runs iterations in batches of size, selecting every leaf in a batch before evaluating any, so that virtual loss spreads them out like threads would.
+func (m *MCTS) grow(ctx context.Context, root *node, board game.Board, turn game.Color, iterations int, size int, random *rand.Rand) (done int) {
+	type leaf struct {
+		path  []*node
+		board game.Board
+		turn  game.Color
+	}
+	batch := make([]leaf, 0, size)
+	for done < iterations && ctx.Err() == nil {
+		batch = batch[:0]
+		for i := 0; i < size && done+i < iterations; i++ {
+			path, leafBoard, leafTurn := m.descend(root, board, turn, random)
+			batch = append(batch, leaf{path: path, board: leafBoard, turn: leafTurn})
+		}
+		for _, l := range batch {
+			m.backup(l.path, m.evaluate(&l.board, l.turn))
+		}
+		done += len(batch)
+	}
+	return done
+}
+
+// descend walks from the root to a node that hasn't been expanded yet, adding virtual loss along the way. It returns the path, and the board at the end of it with whose turn it is.
+func (m *MCTS) descend(root *node, board game.Board, turn game.Color, random *rand.Rand) ([]*node, game.Board, game.Color) {
+	path := []*node{root}
+	n := root
+	for {
+		n.virtual.Add(int64(m.VirtualLoss))
+		if m.expand(n, &board, turn, random) || n.terminal {
+			return path, board, turn
+		}
+		n = m.selectChild(n)
+		board.Apply(n.move, turn)
+		turn = turn.Opponent()
+		path = append(path, n)
+	}
+}
+
+// expand adds the node's children, reporting whether it was this call that did so.
+func (m *MCTS) expand(n *node, board *game.Board, turn game.Color, random *rand.Rand) bool {
+	if n.expanded.Load() {
+		return false
+	}
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.expanded.Load() {
+		return false
+	}
+	moves := board.LegalMoves(turn)
+	// Shuffle, so that unvisited children are tried in a different order by each tree.
+	random.Shuffle(len(moves), func(i, j int) {
+		moves[i], moves[j] = moves[j], moves[i]
+	})
+	n.children = make([]*node, len(moves))
+	for i, move := range moves {
+		n.children[i] = &node{move: move}
+	}
+	n.terminal = len(moves) == 0
+	n.expanded.Store(true)
+	return true
+}
+
+// selectChild picks the child with the highest upper confidence bound, counting virtual losses as visits that scored nothing.
+func (m *MCTS) selectChild(n *node) *node {
+	parentVisits := float64(n.visits.Load() + n.virtual.Load())
+	logParent := math.Log(math.Max(parentVisits, 1))
+	var best *node
+	bestBound := math.Inf(-1)
+	for _, child := range n.children {
+		visits := float64(child.visits.Load() + child.virtual.Load())
+		if visits == 0 {
+			return child
+		}
+		mean := float64(child.value.Load()) / valueUnit / visits
+		bound := mean + m.Exploration*math.Sqrt(logParent/visits)
+		if bound > bestBound {
+			best, bestBound = child, bound
+		}
+	}
+	return best
+}
+
+// evaluate returns the chance that turn wins from the board.
+func (m *MCTS) evaluate(board *game.Board, turn game.Color) float64 {
+	if !board.HasLegalMove(turn) {
+		return 0
+	}
+	if m.Tablebase != nil {
+		if v, ok := m.Tablebase.Probe(board, turn); ok {
+			switch v.Outcome {
+			case tablebase.WIN:
+				return 1
+			case tablebase.LOSS:
+				return 0
+			}
+			return 0.5
+		}
+	}
+	var hash uint64
+	if m.Table != nil {
+		hash = board.Hash(turn)
+		if entry, ok := m.Table.Probe(hash); ok {
+			return float64(entry.Score)
+		}
+	}
+	value := 1 / (1 + math.Exp(-m.Scale*m.Weights.Evaluate(board, turn)))
+	if m.Table != nil {
+		m.Table.Store(Entry{Hash: hash, Score: float32(value), Bound: EXACT})
+	}
+	return value
+}
+
+// backup adds the result to every node on the path, taking off the virtual losses. value is for the player to move at the end of the path.
+func (m *MCTS) backup(path []*node, value float64) {
+	// The last node was moved into by the other player.
+	value = 1 - value
+	for i := len(path) - 1; i >= 0; i-- {
+		n := path[i]
+		n.value.Add(int64(value * valueUnit))
+		n.visits.Add(1)
+		n.virtual.Add(-int64(m.VirtualLoss))
+		value = 1 - value
+	}
+}
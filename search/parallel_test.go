@@ -0,0 +1,128 @@
+package search
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/headblockhead/focus-ai/eval"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+func TestLazySMP(t *testing.T) {
+	ab := NewAlphaBeta(eval.DefaultWeights(), 3, NewTable(4, DEPTH_PREFERRED))
+	ab.Threads = 4
+	b := game.NewStartingBoard()
+	result, err := ab.Search(context.Background(), b, game.RED)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if result.Depth != 3 {
+		t.Errorf("Expected depth 3, got %d", result.Depth)
+	}
+	if err := b.Apply(result.Move, game.RED); err != nil {
+		t.Errorf("Expected a legal move, got %v", err)
+	}
+
+	result, err = ab.Search(context.Background(), winInTwo(), game.RED)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if result.Score != WIN-1 {
+		t.Errorf("Expected the threads to find the win, got %f", result.Score)
+	}
+}
+
+func TestLazySMPDeterministic(t *testing.T) {
+	var first Result
+	for i := 0; i < 3; i++ {
+		ab := NewAlphaBeta(eval.DefaultWeights(), 3, NewTable(4, DEPTH_PREFERRED))
+		ab.Threads = 3
+		ab.Deterministic = true
+		result, err := ab.Search(context.Background(), game.NewStartingBoard(), game.GREEN)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if i == 0 {
+			first = result
+			continue
+		}
+		if !result.Move.Equal(first.Move) || result.Score != first.Score || result.Nodes != first.Nodes {
+			t.Errorf("Expected %v, got %v", first, result)
+		}
+	}
+}
+
+func TestMCTSFindsWin(t *testing.T) {
+	for _, parallelism := range []Parallelism{TREE, ROOT} {
+		m := NewMCTS(eval.DefaultWeights(), 2000)
+		m.Threads = 4
+		m.Parallelism = parallelism
+		m.Table = NewTable(1, ALWAYS_REPLACE)
+		result, err := m.Search(context.Background(), winInTwo(), game.RED)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		x, y := result.Move.Destination()
+		if x != 5 || y != 3 {
+			t.Errorf("Expected a move onto 5, 3 with %v, got %v", parallelism, result.Move)
+		}
+		if result.Score < 0.9 {
+			t.Errorf("Expected a near certain win with %v, got %f", parallelism, result.Score)
+		}
+		if result.Nodes != 2000 {
+			t.Errorf("Expected 2000 iterations with %v, got %d", parallelism, result.Nodes)
+		}
+	}
+}
+
+func TestMCTSDeterministic(t *testing.T) {
+	for _, parallelism := range []Parallelism{TREE, ROOT} {
+		var first Result
+		for i := 0; i < 3; i++ {
+			m := NewMCTS(eval.DefaultWeights(), 500)
+			m.Threads = 4
+			m.Parallelism = parallelism
+			m.Deterministic = true
+			result, err := m.Search(context.Background(), game.NewStartingBoard(), game.RED)
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if i == 0 {
+				first = result
+				continue
+			}
+			if !result.Move.Equal(first.Move) || result.Score != first.Score {
+				t.Errorf("Expected %v with %v, got %v", first, parallelism, result)
+			}
+		}
+	}
+}
+
+func TestMCTSVirtualLoss(t *testing.T) {
+	m := NewMCTS(eval.DefaultWeights(), 0)
+	m.VirtualLoss = 3
+	root := &node{}
+	b := game.NewStartingBoard()
+	random := newTestRand()
+	// Expand the root, then take two more paths without backing up, like two threads would.
+	path, _, _ := m.descend(root, b, game.RED, random)
+	m.backup(path, 0.5)
+	first, _, _ := m.descend(root, b, game.RED, random)
+	second, _, _ := m.descend(root, b, game.RED, random)
+	if first[1] == second[1] {
+		t.Errorf("Expected virtual loss to send the second path elsewhere")
+	}
+	if root.virtual.Load() != 6 {
+		t.Errorf("Expected 2 virtual losses of 3 on the root, got %d", root.virtual.Load())
+	}
+	m.backup(first, 0.5)
+	m.backup(second, 0.5)
+	if root.virtual.Load() != 0 || root.visits.Load() != 3 {
+		t.Errorf("Expected no virtual loss left and 3 visits, got %d and %d", root.virtual.Load(), root.visits.Load())
+	}
+}
+
+func newTestRand() *rand.Rand {
+	return rand.New(rand.NewSource(1))
+}
@@ -0,0 +1,272 @@
+package search
+
+import (
+	"context"
+	"io"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/eval"
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/headblockhead/focus-ai/tablebase"
+)
+
+func TestPackMove(t *testing.T) {
+	b := game.NewStartingBoard()
+	b.SetReserves(game.RED, 1)
+	for x := 1; x < 3; x++ {
+		for i := 0; i < 3; i++ {
+			b.AddPiece(x, 1, game.Piece{Color: game.RED, Exists: true}, game.RED)
+		}
+	}
+	seen := map[PackedMove]bool{}
+	for _, move := range b.LegalMoves(game.RED) {
+		packed := Pack(move)
+		if packed == 0 {
+			t.Errorf("Expected %v not to pack to 0", move)
+		}
+		if seen[packed] {
+			t.Errorf("Expected %v to pack uniquely", move)
+		}
+		seen[packed] = true
+		unpacked, ok := packed.Unpack()
+		if !ok || !unpacked.Equal(move) {
+			t.Errorf("Expected %v, got %v", move, unpacked)
+		}
+	}
+	if _, ok := PackedMove(0).Unpack(); ok {
+		t.Errorf("Expected 0 to be no move")
+	}
+}
+
+func TestTable(t *testing.T) {
+	table := NewTable(1, DEPTH_PREFERRED)
+	table.Store(Entry{Hash: 42, Score: 1, Depth: 3, Bound: EXACT})
+	entry, ok := table.Probe(42)
+	if !ok || entry.Score != 1 || entry.Depth != 3 {
+		t.Errorf("Expected the stored entry, got %v", entry)
+	}
+	// A shallower result for the same position doesn't replace a deeper one.
+	table.Store(Entry{Hash: 42, Score: 2, Depth: 1, Bound: EXACT})
+	entry, _ = table.Probe(42)
+	if entry.Score != 1 {
+		t.Errorf("Expected the deeper entry to be kept, got %v", entry)
+	}
+	// Unless the deeper one is from an earlier search.
+	table.NewSearch()
+	table.Store(Entry{Hash: 42, Score: 2, Depth: 1, Bound: EXACT})
+	entry, _ = table.Probe(42)
+	if entry.Score != 2 {
+		t.Errorf("Expected the new entry, got %v", entry)
+	}
+	_, ok = table.Probe(43)
+	if ok {
+		t.Errorf("Expected a miss")
+	}
+	stats := table.Stats()
+	if stats.Probes != 4 || stats.Hits != 3 || stats.Stores != 3 || stats.Entries != 1 {
+		t.Errorf("Expected 4 probes, 3 hits, 3 stores and 1 entry, got %+v", stats)
+	}
+	if stats.HitRate() != 0.75 {
+		t.Errorf("Expected a hit rate of 0.75, got %f", stats.HitRate())
+	}
+	table.Clear()
+	if _, ok := table.Probe(42); ok || table.Stats().Entries != 0 {
+		t.Errorf("Expected an empty table")
+	}
+}
+
+func TestTableCollisions(t *testing.T) {
+	for _, replacement := range []Replacement{DEPTH_PREFERRED, ALWAYS_REPLACE} {
+		table := NewTable(1, replacement)
+		// Same shard and bucket.
+		buckets := table.mask + 1
+		table.Store(Entry{Hash: 1, Depth: 5})
+		table.Store(Entry{Hash: 1 + buckets, Depth: 1})
+		table.Store(Entry{Hash: 1 + 2*buckets, Depth: 2})
+		_, deep := table.Probe(1)
+		_, recent := table.Probe(1 + 2*buckets)
+		if !recent {
+			t.Errorf("Expected the most recent entry to be kept with %v", replacement)
+		}
+		if deep != (replacement == DEPTH_PREFERRED) {
+			t.Errorf("Expected the deep entry to be kept only when depth is preferred, got %v with %v", deep, replacement)
+		}
+		if table.Stats().Overwrites != 1 {
+			t.Errorf("Expected 1 overwrite, got %d", table.Stats().Overwrites)
+		}
+	}
+}
+
+func TestTableConcurrent(t *testing.T) {
+	table := NewTable(1, DEPTH_PREFERRED)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := uint64(1); j < 1000; j++ {
+				table.Store(Entry{Hash: j*uint64(i+1) + 1, Depth: int8(i)})
+				table.Probe(j)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// winInTwo is a board where RED wins by moving onto GREEN's only stack, which leaves GREEN with nothing to move.
+func winInTwo() game.Board {
+	b := game.NewBoard()
+	b.AddPiece(3, 3, game.Piece{Color: game.RED, Exists: true}, game.RED)
+	b.AddPiece(3, 3, game.Piece{Color: game.RED, Exists: true}, game.RED)
+	b.AddPiece(5, 3, game.Piece{Color: game.GREEN, Exists: true}, game.GREEN)
+	return b
+}
+
+func TestAlphaBetaFindsWin(t *testing.T) {
+	ab := NewAlphaBeta(eval.DefaultWeights(), 3, NewTable(1, DEPTH_PREFERRED))
+	result, err := ab.Search(context.Background(), winInTwo(), game.RED)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	x, y := result.Move.Destination()
+	if x != 5 || y != 3 {
+		t.Errorf("Expected a move onto 5, 3, got %v", result.Move)
+	}
+	if result.Score != WIN-1 {
+		t.Errorf("Expected a win next move, got %f", result.Score)
+	}
+	if result.Depth != 1 {
+		t.Errorf("Expected the search to stop once it found a win, got depth %d", result.Depth)
+	}
+}
+
+func TestAlphaBetaUsesTablebase(t *testing.T) {
+	tb, err := tablebase.Build(2, io.Discard)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// A chase between two pieces, which RED wins in 17 moves, far deeper than the search.
+	b, turn, _ := game.ParsePosition("xx4xx/x6x/7r/8/8/7g/x6x/xx4xx r 0 0")
+	ab := NewAlphaBeta(eval.DefaultWeights(), 2, NewTable(1, DEPTH_PREFERRED))
+	ab.Tablebase = tb
+	result, err := ab.Search(context.Background(), b, turn)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if result.Score != WIN-17 {
+		t.Errorf("Expected a win in 17, got %f", result.Score)
+	}
+	b.Apply(result.Move, turn)
+	if v, _ := tb.Probe(&b, turn.Opponent()); v.Outcome != tablebase.LOSS || v.Distance != 16 {
+		t.Errorf("Expected %v to leave GREEN lost in 16, got %v", result.Move, v)
+	}
+}
+
+func TestAlphaBetaUsesTable(t *testing.T) {
+	table := NewTable(4, DEPTH_PREFERRED)
+	ab := NewAlphaBeta(eval.DefaultWeights(), 3, table)
+	_, err := ab.Search(context.Background(), game.NewStartingBoard(), game.RED)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if table.Stats().Hits == 0 {
+		t.Errorf("Expected transpositions to be found, got %+v", table.Stats())
+	}
+}
+
+func TestAlphaBetaCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ab := NewAlphaBeta(eval.DefaultWeights(), 20, NewTable(1, DEPTH_PREFERRED))
+	b := game.NewStartingBoard()
+	result, err := ab.Search(ctx, b, game.RED)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if result.Depth >= 20 {
+		t.Errorf("Expected the search to stop early, got depth %d", result.Depth)
+	}
+	if err := b.Apply(result.Move, game.RED); err != nil {
+		t.Errorf("Expected a legal move even when cancelled, got %v", err)
+	}
+}
+
+func TestAlphaBetaLimits(t *testing.T) {
+	b := game.NewStartingBoard()
+	ab := NewAlphaBeta(eval.DefaultWeights(), 3, NewTable(1, DEPTH_PREFERRED))
+
+	result, _ := ab.Search(agent.WithLimits(context.Background(), agent.SearchLimits{Nodes: 1000}), b, game.RED)
+	if result.Nodes > 1001 {
+		t.Errorf("Expected at most 1000 nodes, got %d", result.Nodes)
+	}
+
+	result, _ = ab.Search(agent.WithLimits(context.Background(), agent.SearchLimits{Depth: 1}), b, game.RED)
+	if result.Depth != 1 {
+		t.Errorf("Expected the depth limit to replace Depth, got depth %d", result.Depth)
+	}
+
+	start := time.Now()
+	result, _ = ab.Search(agent.WithLimits(context.Background(), agent.SearchLimits{MoveTime: 100 * time.Millisecond}), b, game.RED)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the search to stop after about 100ms, took %v", elapsed)
+	}
+	if result.Depth < 1 {
+		t.Errorf("Expected at least one depth to finish, got %d", result.Depth)
+	}
+
+	// An infinite search only stops when it is told to.
+	ctx, stop := context.WithCancel(agent.WithLimits(context.Background(), agent.SearchLimits{Infinite: true, Depth: 1}))
+	time.AfterFunc(100*time.Millisecond, stop)
+	result, _ = ab.Search(ctx, b, game.RED)
+	if result.Depth < 2 {
+		t.Errorf("Expected an infinite search to ignore the depth limit, got depth %d", result.Depth)
+	}
+	if err := b.Apply(result.Move, game.RED); err != nil {
+		t.Errorf("Expected a legal move once stopped, got %v", err)
+	}
+}
+
+func TestMCTSLimits(t *testing.T) {
+	b := game.NewStartingBoard()
+	m := NewMCTS(eval.DefaultWeights(), 100)
+	result, _ := m.Search(context.Background(), b, game.RED)
+	if result.Nodes != 100 {
+		t.Errorf("Expected the default 100 iterations, got %d", result.Nodes)
+	}
+	result, _ = m.Search(agent.WithLimits(context.Background(), agent.SearchLimits{Nodes: 250}), b, game.RED)
+	if result.Nodes != 250 {
+		t.Errorf("Expected 250 iterations, got %d", result.Nodes)
+	}
+	start := time.Now()
+	result, _ = m.Search(agent.WithLimits(context.Background(), agent.SearchLimits{MoveTime: 50 * time.Millisecond}), b, game.RED)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond || result.Nodes <= 100 {
+		t.Errorf("Expected to search for about 50ms, took %v for %d iterations", elapsed, result.Nodes)
+	}
+}
+
+func TestMCTSPolicy(t *testing.T) {
+	b := game.NewStartingBoard()
+	m := NewMCTS(eval.DefaultWeights(), 500)
+	result, _ := m.Search(context.Background(), b, game.RED)
+	moves := b.LegalMoves(game.RED)
+	if len(result.Policy) != len(moves) {
+		t.Fatalf("Expected a policy for each of the %d moves, got %d", len(moves), len(result.Policy))
+	}
+	total, best := 0.0, 0
+	for i, p := range result.Policy {
+		total += p
+		if p > result.Policy[best] {
+			best = i
+		}
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("Expected the policy to add up to 1, got %v", total)
+	}
+	if !moves[best].Equal(result.Move) {
+		t.Errorf("Expected the move played to have the most policy, got %v over %v", moves[best], result.Move)
+	}
+}
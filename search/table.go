@@ -0,0 +1,211 @@
+package search
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+type Bound uint8
+
+const (
+	// The score is exact.
+	EXACT Bound = iota
+	// The real score is at least this high.
+	LOWER
+	// The real score is at most this high.
+	UPPER
+)
+
+type Replacement int
+
+const (
+	// Keep the entry that was searched deepest, unless it's left over from an earlier search.
+	DEPTH_PREFERRED Replacement = iota
+	// Keep the two most recently stored entries.
+	ALWAYS_REPLACE
+)
+
+// A PackedMove fits a move into 16 bits: reserve flag, from x, from y, pieces, to x, to y. Zero is no move.
+type PackedMove uint16
+
+func Pack(m game.Move) PackedMove {
+	if m.FromReserve {
+		return PackedMove(1<<15 | m.X<<12 | m.Y<<9)
+	}
+	x, y := m.Destination()
+	return PackedMove(m.X<<12 | m.Y<<9 | m.Pieces<<6 | x<<3 | y)
+}
+
+func (p PackedMove) Unpack() (m game.Move, ok bool) {
+	if p == 0 {
+		return m, false
+	}
+	x, y := int(p>>12)&7, int(p>>9)&7
+	if p&(1<<15) != 0 {
+		return game.Move{X: x, Y: y, FromReserve: true}, true
+	}
+	return game.StackMove(x, y, int(p>>6)&7, int(p>>3)&7, int(p)&7), true
+}
+
+// An Entry is what a search learned about a position. Alpha-beta stores bounded scores and the best move;
+// MCTS can store a leaf evaluation as an EXACT entry with a depth of 0.
+type Entry struct {
+	Hash  uint64
+	Score float32
+	Move  PackedMove
+	Depth int8
+	Bound Bound
+	age   uint8
+}
+
+type TableStats struct {
+	Probes     uint64
+	Hits       uint64
+	Stores     uint64
+	Overwrites uint64
+	Entries    int
+}
+
+func (s TableStats) HitRate() float64 {
+	if s.Probes == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Probes)
+}
+
+const shardCount = 64
+
+type shard struct {
+	mutex sync.Mutex
+	// Each bucket has a slot picked by the replacement policy, and a slot that is always replaced.
+	buckets [][2]Entry
+}
+
+// A Table is a fixed-size transposition table. It is split into shards that lock separately, so many searches can share one.
+// A zero hash marks an empty slot, so a position that hashes to zero is never found.
+type Table struct {
+	shards      [shardCount]shard
+	mask        uint64
+	replacement Replacement
+	age         atomic.Uint32
+
+	probes     atomic.Uint64
+	hits       atomic.Uint64
+	stores     atomic.Uint64
+	overwrites atomic.Uint64
+}
+
+// NewTable makes a table that uses about megabytes of memory.
+func NewTable(megabytes int, replacement Replacement) *Table {
+	bucketSize := 2 * 24
+	perShard := megabytes * 1024 * 1024 / bucketSize / shardCount
+	buckets := 1
+	for buckets*2 <= perShard {
+		buckets *= 2
+	}
+	t := &Table{mask: uint64(buckets - 1), replacement: replacement}
+	for i := range t.shards {
+		t.shards[i].buckets = make([][2]Entry, buckets)
+	}
+	return t
+}
+
+func (t *Table) locate(hash uint64) (*shard, uint64) {
+	// The top bits pick the shard and the bottom bits the bucket, so they don't overlap.
+	return &t.shards[hash>>58], hash & t.mask
+}
+
+func (t *Table) Probe(hash uint64) (Entry, bool) {
+	t.probes.Add(1)
+	s, index := t.locate(hash)
+	s.mutex.Lock()
+	bucket := s.buckets[index]
+	s.mutex.Unlock()
+	for _, entry := range bucket {
+		if entry.Hash == hash && hash != 0 {
+			t.hits.Add(1)
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+func (t *Table) Store(entry Entry) {
+	t.stores.Add(1)
+	entry.age = uint8(t.age.Load())
+	s, index := t.locate(entry.Hash)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	bucket := &s.buckets[index]
+
+	for i := range bucket {
+		if bucket[i].Hash == entry.Hash {
+			// Keep a deeper result for the same position, unless it's from an old search.
+			if t.replacement == DEPTH_PREFERRED && i == 0 && bucket[i].Depth > entry.Depth && bucket[i].age == entry.age {
+				return
+			}
+			if entry.Move == 0 {
+				entry.Move = bucket[i].Move
+			}
+			bucket[i] = entry
+			return
+		}
+	}
+
+	replace := 0
+	if t.replacement == ALWAYS_REPLACE || bucket[0].Hash != 0 && bucket[0].age == entry.age && bucket[0].Depth > entry.Depth {
+		replace = 1
+	}
+	if bucket[replace].Hash != 0 {
+		t.overwrites.Add(1)
+	}
+	if t.replacement == ALWAYS_REPLACE {
+		bucket[1] = bucket[0]
+		replace = 0
+	}
+	bucket[replace] = entry
+}
+
+// NewSearch marks everything in the table as coming from an earlier search, so it is replaced first.
+func (t *Table) NewSearch() {
+	t.age.Add(1)
+}
+
+func (t *Table) Clear() {
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.mutex.Lock()
+		for j := range s.buckets {
+			s.buckets[j] = [2]Entry{}
+		}
+		s.mutex.Unlock()
+	}
+	t.probes.Store(0)
+	t.hits.Store(0)
+	t.stores.Store(0)
+	t.overwrites.Store(0)
+}
+
+func (t *Table) Stats() TableStats {
+	stats := TableStats{
+		Probes:     t.probes.Load(),
+		Hits:       t.hits.Load(),
+		Stores:     t.stores.Load(),
+		Overwrites: t.overwrites.Load(),
+	}
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.mutex.Lock()
+		for _, bucket := range s.buckets {
+			for _, entry := range bucket {
+				if entry.Hash != 0 {
+					stats.Entries++
+				}
+			}
+		}
+		s.mutex.Unlock()
+	}
+	return stats
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/headblockhead/focus-ai/arena"
+)
+
+// selfplay has an agent play games against itself, or another agent, saving the records to train and build books from.
+func selfplay(args []string) (err error) {
+	config := arena.DefaultConfig()
+	flags := flag.NewFlagSet("selfplay", flag.ExitOnError)
+	spec := flags.String("agent", "alphabeta", "The agent to play, such as alphabeta:depth=4")
+	opponent := flags.String("opponent", "", "The agent to play against, the same as -agent if empty")
+	records := flags.String("records", "records.jsonl", "File to append the game records to")
+	flags.IntVar(&config.Games, "games", config.Games, "Number of games, colours alternate")
+	flags.IntVar(&config.MaxPlies, "maxplies", config.MaxPlies, "Moves before a game is drawn")
+	flags.IntVar(&config.RandomPlies, "randomplies", config.RandomPlies, "Random moves played before the agents take over")
+	flags.Int64Var(&config.Seed, "seed", config.Seed, "Seed for the random openings")
+	limitFlags(flags, &config.Limits)
+	watch := flags.String("watch", "", "Address to serve the games on while they are played, such as :8080")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *opponent == "" {
+		*opponent = *spec
+	}
+	// Separate agents, so that neither can peek at what the other has learnt in its tables.
+	a, closeA, err := newAgent(*spec)
+	if err != nil {
+		return err
+	}
+	defer closeA()
+	b, closeB, err := newAgent(*opponent)
+	if err != nil {
+		return err
+	}
+	defer closeB()
+	f, err := os.OpenFile(*records, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	config.Records = f
+
+	ctx, err := watchGames(context.Background(), *watch)
+	if err != nil {
+		return err
+	}
+	result, err := arena.Match(ctx, a, b, config)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s against %s: %v\n", *spec, *opponent, result)
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/arena"
+	"github.com/headblockhead/focus-ai/server"
+)
+
+// serve plays games over HTTP, see the server package for what can be asked of it.
+func serve(args []string) (err error) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "Address to listen on")
+	var specs agentList
+	flags.Var(&specs, "agent", "An agent that can be asked for moves, such as fast=alphabeta:depth=2. Without a name it is called by its spec. Give once per agent.")
+	store := flags.String("store", "", "Directory to save games in, so they survive a restart")
+	// A request can ask for a different movetime.
+	var limits agent.SearchLimits
+	limitFlags(flags, &limits)
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	agents := map[string]agent.Agent{}
+	for _, spec := range specs {
+		// A name is only given before an = that comes before any options.
+		name := spec
+		if before, after, ok := strings.Cut(spec, "="); ok && !strings.Contains(before, ":") {
+			name, spec = before, after
+		}
+		if _, ok := agents[name]; ok {
+			return fmt.Errorf("There is already an agent called %q", name)
+		}
+		var closeAgent func()
+		agents[name], closeAgent, err = newAgent(spec)
+		if err != nil {
+			return err
+		}
+		defer closeAgent()
+	}
+	var s *server.Server
+	if *store != "" {
+		s, err = server.New(agents, server.FileStore{Dir: *store})
+	} else {
+		s, err = server.New(agents, nil)
+	}
+	if err != nil {
+		return err
+	}
+	s.Limits = limits
+	fmt.Printf("Listening on %s\n", *addr)
+	return http.ListenAndServe(*addr, s)
+}
+
+// watchGames serves every game played with the returned context on addr, so they can be watched as they happen. An empty addr serves nothing.
+func watchGames(ctx context.Context, addr string) (context.Context, error) {
+	if addr == "" {
+		return ctx, nil
+	}
+	s, err := server.New(nil, nil)
+	if err != nil {
+		return ctx, err
+	}
+	go func() {
+		if err := http.ListenAndServe(addr, s); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to serve games: %v\n", err)
+		}
+	}()
+	fmt.Fprintf(os.Stderr, "Watch the games on %s\n", addr)
+	return arena.WithSpectators(ctx, s.Spectate), nil
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+var (
+	ErrGameOver     = errors.New("The game is over")
+	ErrGameNotFound = errors.New("There is no game with that ID")
+	ErrGameChanged  = errors.New("The game changed while the agent was thinking")
+	ErrGameWatched  = errors.New("The game is being played elsewhere, it can only be watched")
+	ErrGameNotSaved = errors.New("The game couldn't be saved, so the move wasn't played")
+)
+
+// Game is a game being played on the server.
+type Game struct {
+	ID    string
+	mutex sync.Mutex
+	// record holds the whole game, and board and turn the position it has reached.
+	record game.Record
+	board  game.Board
+	turn   game.Color
+	// watched is set for games played elsewhere, which the server only passes on.
+	watched  bool
+	watchers map[chan Event]struct{}
+}
+
+func newGame(id string, record game.Record) (g *Game, err error) {
+	g = &Game{ID: id, record: record, watchers: map[chan Event]struct{}{}}
+	boards, err := record.Positions()
+	if err != nil {
+		return nil, err
+	}
+	g.board = boards[len(boards)-1]
+	g.turn = record.TurnAt(len(record.Moves))
+	g.checkOver()
+	return g, nil
+}
+
+// State is what a client is told about a game.
+type State struct {
+	ID string
+	// Position is the board and whose turn it is in the notation of the game package.
+	Position string
+	Board    game.Board
+	Turn     string
+	Ply      int
+	Moves    []string
+	Result   string
+	Reason   string
+	Red      string
+	Green    string
+}
+
+func (g *Game) State() State {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.state()
+}
+
+func (g *Game) state() State {
+	moves := make([]string, len(g.record.Moves))
+	for i, move := range g.record.Moves {
+		moves[i] = move.String()
+	}
+	return State{
+		ID:       g.ID,
+		Position: game.FormatPosition(g.board, g.turn),
+		Board:    g.board,
+		Turn:     g.turn.String(),
+		Ply:      len(g.record.Moves),
+		Moves:    moves,
+		Result:   g.record.Result.String(),
+		Reason:   g.record.Reason,
+		Red:      g.record.Red,
+		Green:    g.record.Green,
+	}
+}
+
+// Record returns a copy of the whole game so far.
+func (g *Game) Record() game.Record {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	record := g.record
+	record.Moves = append([]game.Move{}, g.record.Moves...)
+	return record
+}
+
+// LegalMoves lists the moves the player to move can make, which is none once the game is over.
+func (g *Game) LegalMoves() []game.Move {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.record.Result != game.UNDECIDED {
+		return nil
+	}
+	return g.board.LegalMoves(g.turn)
+}
+
+// Position returns the board, whose turn it is and how many moves have been played.
+func (g *Game) Position() (board game.Board, turn game.Color, ply int) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.board, g.turn, len(g.record.Moves)
+}
+
+// Play makes a move for the player to move. If ply is not -1 it must be the number of moves played so far, so a move chosen for an earlier position is refused.
+// save, if set, is given the game with the move played before anyone else sees it, and if it fails the move is taken back.
+func (g *Game) Play(move game.Move, ply int, save func(record game.Record) error) (err error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if ply >= 0 && ply != len(g.record.Moves) {
+		return ErrGameChanged
+	}
+	if g.watched {
+		return ErrGameWatched
+	}
+	if g.record.Result != game.UNDECIDED {
+		return ErrGameOver
+	}
+	legal := false
+	for _, m := range g.board.LegalMoves(g.turn) {
+		if m.Equal(move) {
+			legal = true
+			break
+		}
+	}
+	if !legal {
+		return fmt.Errorf("%v is not a legal move for %v", move, g.turn)
+	}
+	board, turn, record := g.board, g.turn, g.record
+	if err = g.board.Apply(move, g.turn); err != nil {
+		return err
+	}
+	g.record.Moves = append(g.record.Moves, move)
+	g.turn = g.turn.Opponent()
+	g.checkOver()
+	if save != nil {
+		if err = save(g.record); err != nil {
+			g.board, g.turn, g.record = board, turn, record
+			return fmt.Errorf("%w: %v", ErrGameNotSaved, err)
+		}
+	}
+	g.moved()
+	return nil
+}
+
+// checkOver ends the game if the player to move has no moves.
+func (g *Game) checkOver() {
+	if g.record.Result == game.UNDECIDED && !g.board.HasLegalMove(g.turn) {
+		g.record.Result = game.WinFor(g.turn.Opponent())
+		g.record.Reason = fmt.Sprintf("%v has no legal moves", g.turn)
+	}
+}
@@ -0,0 +1,20 @@
+module github.com/headblockhead/focus-ai/server
+
+require (
+	github.com/headblockhead/focus-ai/agent v0.0.0
+	github.com/headblockhead/focus-ai/arena v0.0.0
+	github.com/headblockhead/focus-ai/game v0.0.0
+	golang.org/x/net v0.17.0
+)
+
+require github.com/headblockhead/focus-ai/eval v0.0.0 // indirect
+
+replace github.com/headblockhead/focus-ai/game v0.0.0 => ../game
+
+replace github.com/headblockhead/focus-ai/eval v0.0.0 => ../eval
+
+replace github.com/headblockhead/focus-ai/agent v0.0.0 => ../agent
+
+replace github.com/headblockhead/focus-ai/arena v0.0.0 => ../arena
+
+go 1.20
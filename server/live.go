@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	_ "embed"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/arena"
+	"github.com/headblockhead/focus-ai/game"
+	"golang.org/x/net/websocket"
+)
+
+//go:embed watch.html
+var watchPage []byte
+
+// An Event is sent to the spectators of a game as JSON over a WebSocket.
+type Event struct {
+	// Type is "state" when a spectator joins, "move" after every move, "info" while an agent is thinking and "end" once the game is over.
+	Type string
+	// Move is the move just played, for "move".
+	Move  string
+	State *State
+	Info  *Info
+}
+
+// Info is the progress of the agent thinking about the next move.
+type Info struct {
+	Turn  string
+	Depth int
+	Score float64
+	Nodes uint64
+	// Time is in milliseconds.
+	Time int64
+	PV   []string
+}
+
+// Watch returns the events of the game, starting with its state, until stop is called.
+// A spectator that falls behind misses events, but every move comes with the whole state of the game.
+func (g *Game) Watch() (events <-chan Event, stop func()) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	c := make(chan Event, 64)
+	state := g.state()
+	c <- Event{Type: "state", State: &state}
+	g.watchers[c] = struct{}{}
+	return c, func() {
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+		delete(g.watchers, c)
+	}
+}
+
+// publish sends the event to every spectator with room for it. The game must be locked.
+func (g *Game) publish(e Event) {
+	for c := range g.watchers {
+		select {
+		case c <- e:
+		default:
+		}
+	}
+}
+
+// moved tells spectators about the last move, and the end of the game if it is over. The game must be locked.
+func (g *Game) moved() {
+	state := g.state()
+	g.publish(Event{Type: "move", Move: state.Moves[len(state.Moves)-1], State: &state})
+	if g.record.Result != game.UNDECIDED {
+		g.publish(Event{Type: "end", State: &state})
+	}
+}
+
+// report tells spectators what the agent to move is thinking.
+func (g *Game) report(info agent.Info) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	pv := make([]string, len(info.PV))
+	for i, move := range info.PV {
+		pv[i] = move.String()
+	}
+	g.publish(Event{Type: "info", Info: &Info{
+		Turn:  g.turn.String(),
+		Depth: info.Depth,
+		Score: info.Score,
+		Nodes: info.Nodes,
+		Time:  info.Time.Milliseconds(),
+		PV:    pv,
+	}})
+}
+
+// live streams the game's events to a WebSocket until the spectator goes away.
+func (s *Server) live(w http.ResponseWriter, r *http.Request, g *Game) {
+	// Without a Handshake any origin is allowed, so pages served from elsewhere can watch too.
+	websocket.Server{Handler: func(ws *websocket.Conn) {
+		events, stop := g.Watch()
+		defer stop()
+		// Nothing is expected from the spectator, but reading notices when it leaves.
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		go func() {
+			io.Copy(io.Discard, ws)
+			cancel()
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-events:
+				if err := websocket.JSON.Send(ws, e); err != nil {
+					return
+				}
+			}
+		}
+	}}.ServeHTTP(w, r)
+}
+
+// Spectate adds a game being played elsewhere, such as by arena.PlayTimed, and returns the spectator that keeps it up to date.
+// It can be given to arena.WithSpectators, so that games can be watched while they are played.
+func (s *Server) Spectate(record game.Record) arena.Spectator {
+	id, err := newID()
+	if err != nil {
+		// Watching is a nicety, so a game without an ID just isn't shown.
+		return nil
+	}
+	g, err := newGame(id, record)
+	if err != nil {
+		return nil
+	}
+	g.watched = true
+	s.add(g)
+	return spectator{server: s, game: g}
+}
+
+type spectator struct {
+	server *Server
+	game   *Game
+}
+
+func (sp spectator) Moved(record game.Record, board game.Board, turn game.Color) {
+	sp.game.mutex.Lock()
+	sp.game.record, sp.game.board, sp.game.turn = record, board, turn
+	sp.game.moved()
+	sp.game.mutex.Unlock()
+}
+
+func (sp spectator) Info(info agent.Info) {
+	sp.game.report(info)
+}
+
+func (sp spectator) Finished(record game.Record) {
+	sp.game.mutex.Lock()
+	sp.game.record = record
+	if record.Result == game.UNDECIDED && record.Reason == "" {
+		sp.game.record.Reason = "abandoned"
+	}
+	state := sp.game.state()
+	sp.game.publish(Event{Type: "end", State: &state})
+	sp.game.mutex.Unlock()
+	sp.server.save(sp.game)
+	// Games keep being played elsewhere, such as in a long self-play run, so finished ones can't all be kept.
+	time.AfterFunc(sp.server.KeepWatched, func() {
+		sp.server.remove(sp.game)
+	})
+}
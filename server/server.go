@@ -0,0 +1,357 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// Server plays games over HTTP. Every request and response body is JSON.
+//
+//	GET  /agents                 The names of the agents that can be asked for moves.
+//	GET  /games                  A summary of every game.
+//	POST /games                  Start a game: {"Position": "start", "Red": "...", "Green": "..."}, all optional.
+//	GET  /games/{id}             The game's State.
+//	GET  /games/{id}/moves       The legal moves.
+//	POST /games/{id}/moves       Play a move: {"Move": "b2-c2"}.
+//	POST /games/{id}/ai          Ask an agent to play the next move: {"Agent": "name", "MoveTime": milliseconds}.
+//	GET  /games/{id}/record      The whole game as a game.Record.
+//	GET  /games/{id}/live        A WebSocket of the game's Events as they happen.
+//	GET  /                       A page for watching games in a browser.
+//
+// Errors are sent as {"Error": "..."}.
+type Server struct {
+	Agents map[string]agent.Agent
+	// Limits are given to agents asked for a move, unless the request has a MoveTime.
+	Limits agent.SearchLimits
+	// Store, if set, has every game saved to it after every change.
+	Store Store
+	// KeepWatched is how long a game played elsewhere stays once it has finished, so that spectators can see how it ended, before it is forgotten to make room for the next.
+	KeepWatched time.Duration
+	mutex       sync.Mutex
+	games       map[string]*Game
+}
+
+// New makes a server for the agents, loading any games saved in the store, which may be nil.
+func New(agents map[string]agent.Agent, store Store) (s *Server, err error) {
+	s = &Server{Agents: agents, Store: store, KeepWatched: time.Minute, games: map[string]*Game{}}
+	if store == nil {
+		return s, nil
+	}
+	records, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	for id, record := range records {
+		if s.games[id], err = newGame(id, record); err != nil {
+			return nil, fmt.Errorf("game %s: %w", id, err)
+		}
+	}
+	return s, nil
+}
+
+// Game returns the game with the ID, or nil if there isn't one.
+func (s *Server) Game(id string) *Game {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.games[id]
+}
+
+// NewGame starts a game from the position, with the names of its players.
+func (s *Server) NewGame(board game.Board, turn game.Color, red string, green string) (g *Game, err error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	g, err = newGame(id, game.Record{Red: red, Green: green, Start: board, Turn: turn})
+	if err != nil {
+		return nil, err
+	}
+	// Only games that were saved are played, so a client trying again doesn't leave one behind.
+	if err = s.save(g); err != nil {
+		return nil, err
+	}
+	s.add(g)
+	return g, nil
+}
+
+func newID() (string, error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+func (s *Server) add(g *Game) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.games[g.ID] = g
+}
+
+// remove forgets the game, unless another has since taken its ID.
+func (s *Server) remove(g *Game) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.games[g.ID] == g {
+		delete(s.games, g.ID)
+	}
+}
+
+func (s *Server) save(g *Game) error {
+	if s.Store == nil {
+		return nil
+	}
+	return s.Store.Save(g.ID, g.Record())
+}
+
+// saver saves the game as it is played, for Game.Play.
+func (s *Server) saver(g *Game) func(record game.Record) error {
+	if s.Store == nil {
+		return nil
+	}
+	return func(record game.Record) error {
+		return s.Store.Save(g.ID, record)
+	}
+}
+
+// An httpError is an error with the status code to send it with.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e httpError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(err error) error {
+	return httpError{status: http.StatusBadRequest, err: err}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The page and the live events aren't JSON, so they are dealt with first.
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if r.Method == http.MethodGet && r.URL.Path == "/" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(watchPage)
+		return
+	}
+	if r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "games" && parts[2] == "live" {
+		if g := s.Game(parts[1]); g != nil {
+			s.live(w, r, g)
+			return
+		}
+	}
+	status, response, err := s.route(r)
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		status = http.StatusInternalServerError
+		var he httpError
+		if errors.As(err, &he) {
+			status = he.status
+		} else if errors.Is(err, ErrGameNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, ErrGameOver) || errors.Is(err, ErrGameChanged) || errors.Is(err, ErrGameWatched) {
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(struct{ Error string }{err.Error()})
+		return
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// route works out what the request is for, and returns the status and response to send.
+func (s *Server) route(r *http.Request) (status int, response any, err error) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "agents" && r.Method == http.MethodGet:
+		names := []string{}
+		for name := range s.Agents {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return http.StatusOK, names, nil
+	case len(parts) == 1 && parts[0] == "games" && r.Method == http.MethodGet:
+		return http.StatusOK, s.summaries(), nil
+	case len(parts) == 1 && parts[0] == "games" && r.Method == http.MethodPost:
+		response, err = s.create(r)
+		return http.StatusCreated, response, err
+	case len(parts) < 2 || len(parts) > 3 || parts[0] != "games":
+		return 0, nil, httpError{status: http.StatusNotFound, err: fmt.Errorf("Nothing at %s", r.URL.Path)}
+	}
+
+	g := s.Game(parts[1])
+	if g == nil {
+		return 0, nil, ErrGameNotFound
+	}
+	action := ""
+	if len(parts) == 3 {
+		action = parts[2]
+	}
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		response = g.State()
+	case action == "moves" && r.Method == http.MethodGet:
+		response = legalMoves(g.LegalMoves())
+	case action == "moves" && r.Method == http.MethodPost:
+		response, err = s.play(g, r)
+	case action == "ai" && r.Method == http.MethodPost:
+		response, err = s.think(g, r)
+	case action == "record" && r.Method == http.MethodGet:
+		response = g.Record()
+	default:
+		return 0, nil, httpError{status: http.StatusMethodNotAllowed, err: fmt.Errorf("Can't %s %s", r.Method, r.URL.Path)}
+	}
+	return http.StatusOK, response, err
+}
+
+// A Summary is a game in the list of games.
+type Summary struct {
+	ID     string
+	Turn   string
+	Ply    int
+	Result string
+}
+
+func (s *Server) summaries() (summaries []Summary) {
+	s.mutex.Lock()
+	games := make([]*Game, 0, len(s.games))
+	for _, g := range s.games {
+		games = append(games, g)
+	}
+	s.mutex.Unlock()
+	summaries = []Summary{}
+	for _, g := range games {
+		state := g.State()
+		summaries = append(summaries, Summary{ID: state.ID, Turn: state.Turn, Ply: state.Ply, Result: state.Result})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].ID < summaries[j].ID })
+	return summaries
+}
+
+// decode reads the request body into v. An empty body leaves v as it is.
+func decode(r *http.Request, v any) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil && err != io.EOF {
+		return badRequest(err)
+	}
+	return nil
+}
+
+func (s *Server) create(r *http.Request) (response any, err error) {
+	var request struct {
+		Position string
+		Red      string
+		Green    string
+	}
+	if err = decode(r, &request); err != nil {
+		return nil, err
+	}
+	if request.Position == "" {
+		request.Position = "start"
+	}
+	board, turn, err := game.ParsePosition(request.Position)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	g, err := s.NewGame(board, turn, request.Red, request.Green)
+	if err != nil {
+		return nil, err
+	}
+	return g.State(), nil
+}
+
+// A LegalMove is a move written in notation, along with where it goes from and to.
+type LegalMove struct {
+	Move        string
+	X           int
+	Y           int
+	ToX         int
+	ToY         int
+	Pieces      int
+	FromReserve bool
+}
+
+func legalMoves(moves []game.Move) (legal []LegalMove) {
+	legal = []LegalMove{}
+	for _, move := range moves {
+		toX, toY := move.Destination()
+		legal = append(legal, LegalMove{Move: move.String(), X: move.X, Y: move.Y, ToX: toX, ToY: toY, Pieces: move.Pieces, FromReserve: move.FromReserve})
+	}
+	return legal
+}
+
+func (s *Server) play(g *Game, r *http.Request) (response any, err error) {
+	var request struct {
+		Move string
+	}
+	if err = decode(r, &request); err != nil {
+		return nil, err
+	}
+	move, err := game.ParseMove(request.Move)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	if err = g.Play(move, -1, s.saver(g)); err != nil {
+		if errors.Is(err, ErrGameOver) || errors.Is(err, ErrGameWatched) || errors.Is(err, ErrGameNotSaved) {
+			return nil, err
+		}
+		return nil, badRequest(err)
+	}
+	return g.State(), nil
+}
+
+// An AIMove is the move an agent played, and the game after it.
+type AIMove struct {
+	Move  string
+	State State
+}
+
+func (s *Server) think(g *Game, r *http.Request) (response any, err error) {
+	var request struct {
+		Agent    string
+		MoveTime int
+	}
+	if err = decode(r, &request); err != nil {
+		return nil, err
+	}
+	a, ok := s.Agents[request.Agent]
+	if !ok {
+		return nil, badRequest(fmt.Errorf("There is no agent called %q", request.Agent))
+	}
+	limits := s.Limits
+	if request.MoveTime > 0 {
+		limits = agent.SearchLimits{MoveTime: time.Duration(request.MoveTime) * time.Millisecond}
+	}
+	// Think without holding the game, so it can still be looked at meanwhile.
+	board, turn, ply := g.Position()
+	ctx := agent.WithInfo(r.Context(), g.report)
+	if !limits.IsZero() {
+		ctx = agent.WithLimits(ctx, limits)
+	}
+	move, err := a.SelectMove(ctx, board, turn)
+	if errors.Is(err, agent.ErrNoLegalMoves) {
+		return nil, ErrGameOver
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = g.Play(move, ply, s.saver(g)); err != nil {
+		return nil, err
+	}
+	return AIMove{Move: move.String(), State: g.State()}, nil
+}
@@ -0,0 +1,246 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/arena"
+	"github.com/headblockhead/focus-ai/game"
+	"golang.org/x/net/websocket"
+)
+
+// request sends a request to the server, decoding the response into v if it isn't nil, and returns the status.
+func request(t *testing.T, s *Server, method string, path string, body string, v any) int {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+	if v != nil {
+		if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+			t.Fatalf("Expected JSON from %s %s, got %v", method, path, err)
+		}
+	}
+	return w.Code
+}
+
+func TestServer(t *testing.T) {
+	store := FileStore{Dir: t.TempDir()}
+	s, err := New(map[string]agent.Agent{"random": agent.NewRandom(1)}, store)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var state State
+	if status := request(t, s, http.MethodPost, "/games", `{"Red": "alice"}`, &state); status != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", status)
+	}
+	if state.Position != game.StartingPosition || state.Turn != "RED" || state.Red != "alice" {
+		t.Errorf("Expected a new game with red to move, got %+v", state)
+	}
+	path := "/games/" + state.ID
+
+	var moves []LegalMove
+	request(t, s, http.MethodGet, path+"/moves", "", &moves)
+	if len(moves) != 68 {
+		t.Errorf("Expected 68 legal moves, got %d", len(moves))
+	}
+
+	if status := request(t, s, http.MethodPost, path+"/moves", `{"Move": "`+moves[0].Move+`"}`, &state); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if state.Ply != 1 || state.Turn != "GREEN" {
+		t.Errorf("Expected green to move after one move, got %+v", state)
+	}
+	if status := request(t, s, http.MethodPost, path+"/moves", `{"Move": "b2-b1"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("Expected an illegal move to be refused with 400, got %d", status)
+	}
+	if status := request(t, s, http.MethodPost, path+"/moves", `{"Move": "nonsense"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("Expected an unreadable move to be refused with 400, got %d", status)
+	}
+
+	var ai AIMove
+	if status := request(t, s, http.MethodPost, path+"/ai", `{"Agent": "random"}`, &ai); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if ai.State.Ply != 2 || ai.State.Moves[1] != ai.Move {
+		t.Errorf("Expected the agent's move to be played, got %+v", ai)
+	}
+	if status := request(t, s, http.MethodPost, path+"/ai", `{"Agent": "nobody"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("Expected an unknown agent to be refused with 400, got %d", status)
+	}
+
+	var record game.Record
+	request(t, s, http.MethodGet, path+"/record", "", &record)
+	if len(record.Moves) != 2 || record.Moves[0].String() != moves[0].Move {
+		t.Errorf("Expected the record of both moves, got %+v", record.Moves)
+	}
+
+	if status := request(t, s, http.MethodGet, "/games/missing", "", nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing game, got %d", status)
+	}
+
+	// The game should still be there after a restart.
+	restarted, err := New(nil, store)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var summaries []Summary
+	request(t, restarted, http.MethodGet, "/games", "", &summaries)
+	if len(summaries) != 1 || summaries[0].ID != state.ID || summaries[0].Ply != 2 {
+		t.Errorf("Expected the saved game to be loaded, got %+v", summaries)
+	}
+}
+
+func TestGameOver(t *testing.T) {
+	s, err := New(nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Red's only piece can move onto green's, leaving green with nothing.
+	var state State
+	request(t, s, http.MethodPost, "/games", `{"Position": "xx4xx/xrg4x/8/8/8/8/x6x/xx4xx r 0 0"}`, &state)
+	path := "/games/" + state.ID
+	if status := request(t, s, http.MethodPost, path+"/moves", `{"Move": "b2-c2"}`, &state); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if state.Result != game.RED_WON.String() {
+		t.Errorf("Expected red to win, got %+v", state)
+	}
+	var moves []LegalMove
+	request(t, s, http.MethodGet, path+"/moves", "", &moves)
+	if len(moves) != 0 {
+		t.Errorf("Expected no legal moves once the game is over, got %d", len(moves))
+	}
+	if status := request(t, s, http.MethodPost, path+"/moves", `{"Move": "c2-c3"}`, nil); status != http.StatusConflict {
+		t.Errorf("Expected 409 once the game is over, got %d", status)
+	}
+}
+
+// failingStore fails to save while failing is set.
+type failingStore struct {
+	failing bool
+}
+
+func (fs *failingStore) Save(id string, record game.Record) error {
+	if fs.failing {
+		return errors.New("Disk full")
+	}
+	return nil
+}
+
+func (fs *failingStore) Load() (map[string]game.Record, error) {
+	return nil, nil
+}
+
+func TestSaveFailure(t *testing.T) {
+	store := &failingStore{}
+	s, err := New(nil, store)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	store.failing = true
+	if status := request(t, s, http.MethodPost, "/games", `{}`, nil); status != http.StatusInternalServerError {
+		t.Fatalf("Expected 500 when the game can't be saved, got %d", status)
+	}
+	var summaries []Summary
+	request(t, s, http.MethodGet, "/games", "", &summaries)
+	if len(summaries) != 0 {
+		t.Errorf("Expected the game that wasn't saved to be left out, got %+v", summaries)
+	}
+
+	store.failing = false
+	var state State
+	request(t, s, http.MethodPost, "/games", `{}`, &state)
+	path := "/games/" + state.ID
+
+	store.failing = true
+	if status := request(t, s, http.MethodPost, path+"/moves", `{"Move": "b2-c2"}`, nil); status != http.StatusInternalServerError {
+		t.Fatalf("Expected 500 when the move can't be saved, got %d", status)
+	}
+	request(t, s, http.MethodGet, path, "", &state)
+	if state.Ply != 0 || state.Turn != "RED" {
+		t.Errorf("Expected the move to be taken back, got %+v", state)
+	}
+	// Trying again once saving works should play the move, not refuse it as out of turn.
+	store.failing = false
+	if status := request(t, s, http.MethodPost, path+"/moves", `{"Move": "b2-c2"}`, &state); status != http.StatusOK || state.Ply != 1 {
+		t.Errorf("Expected the move to be played when tried again, got %d, %+v", status, state)
+	}
+}
+
+func TestLive(t *testing.T) {
+	s, err := New(map[string]agent.Agent{"random": agent.NewRandom(1)}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	g, err := s.NewGame(game.NewStartingBoard(), game.RED, "", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	hs := httptest.NewServer(s)
+	defer hs.Close()
+	ws, err := websocket.Dial(strings.Replace(hs.URL, "http", "ws", 1)+"/games/"+g.ID+"/live", "", hs.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer ws.Close()
+
+	var e Event
+	if err = websocket.JSON.Receive(ws, &e); err != nil || e.Type != "state" || e.State.Ply != 0 {
+		t.Fatalf("Expected the state first, got %+v, %v", e, err)
+	}
+	b := game.NewStartingBoard()
+	moves := b.LegalMoves(game.RED)
+	if err = g.Play(moves[0], -1, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err = websocket.JSON.Receive(ws, &e); err != nil || e.Type != "move" || e.State.Ply != 1 || e.Move != e.State.Moves[0] {
+		t.Errorf("Expected the move, got %+v, %v", e, err)
+	}
+	g.report(agent.Info{Depth: 3, Score: 0.5, PV: moves[1:2]})
+	if err = websocket.JSON.Receive(ws, &e); err != nil || e.Type != "info" || e.Info.Depth != 3 || e.Info.Turn != "GREEN" || len(e.Info.PV) != 1 {
+		t.Errorf("Expected the info, got %+v, %v", e, err)
+	}
+}
+
+func TestSpectate(t *testing.T) {
+	s, err := New(nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ctx := arena.WithSpectators(context.Background(), s.Spectate)
+	record, err := arena.Play(ctx, agent.NewRandom(1), agent.NewRandom(2), arena.Opening{Board: game.NewStartingBoard(), Turn: game.RED}, 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var summaries []Summary
+	request(t, s, http.MethodGet, "/games", "", &summaries)
+	if len(summaries) != 1 || summaries[0].Ply != len(record.Moves) || summaries[0].Result != record.Result.String() {
+		t.Fatalf("Expected the played game to be listed, got %+v", summaries)
+	}
+	g := s.Game(summaries[0].ID)
+	_, _, ply := g.Position()
+	if ply != len(record.Moves) {
+		t.Errorf("Expected %d moves, got %d", len(record.Moves), ply)
+	}
+	if status := request(t, s, http.MethodPost, "/games/"+g.ID+"/moves", `{"Move": "b2-c2"}`, nil); status != http.StatusConflict {
+		t.Errorf("Expected moves to be refused with 409, got %d", status)
+	}
+
+	// Once finished games have been kept long enough, they are forgotten.
+	s.KeepWatched = 10 * time.Millisecond
+	if _, err = arena.Play(ctx, agent.NewRandom(1), agent.NewRandom(2), arena.Opening{Board: game.NewStartingBoard(), Turn: game.RED}, 10); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	request(t, s, http.MethodGet, "/games", "", &summaries)
+	if len(summaries) != 1 || summaries[0].ID != g.ID {
+		t.Errorf("Expected only the game kept for longer to be left, got %+v", summaries)
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// A Store keeps games when the server isn't running.
+type Store interface {
+	Save(id string, record game.Record) error
+	// Load returns every saved game by ID.
+	Load() (map[string]game.Record, error)
+}
+
+// FileStore saves each game as a JSON record in a directory, named after its ID.
+type FileStore struct {
+	Dir string
+}
+
+func (fs FileStore) Save(id string, record game.Record) (err error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first, so a crash can't leave half a game behind.
+	path := filepath.Join(fs.Dir, id+".json")
+	if err = os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func (fs FileStore) Load() (records map[string]game.Record, err error) {
+	if err = os.MkdirAll(fs.Dir, 0755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(fs.Dir)
+	if err != nil {
+		return nil, err
+	}
+	records = map[string]game.Record{}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(fs.Dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var record game.Record
+		if err = json.Unmarshal(data, &record); err != nil {
+			return nil, err
+		}
+		records[id] = record
+	}
+	return records, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/headblockhead/focus-ai/tablebase"
+)
+
+// buildTablebase builds a tablebase file, or looks a position up in one.
+func buildTablebase(args []string) (err error) {
+	flags := flag.NewFlagSet("tablebase", flag.ExitOnError)
+	pieces := flags.Int("pieces", 3, "Most pieces, on the board and in reserve, in a position")
+	file := flags.String("file", "tablebase.bin", "File to write the tablebase to, or to probe")
+	probe := flags.String("probe", "", "Position to look up in the file instead of building it")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *probe == "" {
+		tb, err := tablebase.Build(*pieces, os.Stdout)
+		if err != nil {
+			return err
+		}
+		return tablebase.Save(*file, tb)
+	}
+	tb, err := tablebase.Load(*file)
+	if err != nil {
+		return err
+	}
+	board, turn, err := game.ParsePosition(*probe)
+	if err != nil {
+		return err
+	}
+	v, ok := tb.Probe(&board, turn)
+	if !ok {
+		return fmt.Errorf("The position has %d pieces, but the tablebase only goes up to %d", tablebase.Material(&board), tb.Pieces)
+	}
+	fmt.Printf("%v to move: %v\n", turn, v)
+	if move, _, ok := tb.BestMove(&board, turn); ok {
+		fmt.Printf("Best move: %v\n", move)
+	}
+	return nil
+}
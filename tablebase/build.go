@@ -0,0 +1,164 @@
+package tablebase
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+var (
+	ErrHashCollision = errors.New("Two positions have the same hash")
+	ErrTooFewPieces  = errors.New("A tablebase needs at least 1 piece")
+)
+
+// Build works out the value of every position with up to pieces pieces, writing its progress to log.
+// Each extra piece makes it roughly 20 times bigger: 3 pieces is about 430 thousand positions, 4 is about 10 million.
+func Build(pieces int, log io.Writer) (t *Tablebase, err error) {
+	if pieces < 1 {
+		return nil, ErrTooFewPieces
+	}
+	start := time.Now()
+	t = &Tablebase{Pieces: pieces}
+
+	// Positions are found in the same order every time, so rather than keep every board, enumerate them again when they are needed.
+	enumerate(pieces, func(b *game.Board, turn game.Color) {
+		t.hashes = append(t.hashes, b.Hash(turn))
+	})
+	sort.Slice(t.hashes, func(i, j int) bool { return t.hashes[i] < t.hashes[j] })
+	for i := 1; i < len(t.hashes); i++ {
+		if t.hashes[i] == t.hashes[i-1] {
+			return nil, ErrHashCollision
+		}
+	}
+	fmt.Fprintf(log, "Found %d positions in %v\n", len(t.hashes), time.Since(start).Round(time.Millisecond))
+
+	// The moves from each position, as indexes into hashes, with the moves from the nth position found at successors[offsets[n]:offsets[n+1]].
+	var positions, successors []int32
+	offsets := []int32{0}
+	enumerate(pieces, func(b *game.Board, turn game.Color) {
+		if err != nil {
+			return
+		}
+		positions = append(positions, int32(t.index(b.Hash(turn))))
+		for _, move := range b.LegalMoves(turn) {
+			child := *b
+			child.Apply(move, turn)
+			i := t.index(child.Hash(turn.Opponent()))
+			if i < 0 {
+				err = fmt.Errorf("The move %v from %q leads out of the tablebase", move, game.FormatPosition(*b, turn))
+				return
+			}
+			successors = append(successors, int32(i))
+		}
+		offsets = append(offsets, int32(len(successors)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(log, "Generated %d moves in %v\n", len(successors), time.Since(start).Round(time.Millisecond))
+
+	// Positions without moves are lost. Then, at each distance, a position is won if a move reaches a position lost at a shorter distance,
+	// and lost if every move reaches a position won at a shorter distance. Whatever is left when nothing changes is a draw.
+	const undecided = -1
+	distances := make([]int, len(t.hashes))
+	outcomes := make([]Outcome, len(t.hashes))
+	for i := range distances {
+		distances[i] = undecided
+	}
+	for n, position := range positions {
+		if offsets[n] == offsets[n+1] {
+			outcomes[position], distances[position] = LOSS, 0
+		}
+	}
+	for distance := 1; ; distance++ {
+		changed := 0
+		for n, position := range positions {
+			if distances[position] != undecided {
+				continue
+			}
+			allWon := true
+			for _, s := range successors[offsets[n]:offsets[n+1]] {
+				decided := distances[s] != undecided && distances[s] < distance
+				if decided && outcomes[s] == LOSS {
+					outcomes[position], distances[position] = WIN, distance
+					changed++
+					allWon = false
+					break
+				}
+				if !decided {
+					allWon = false
+				}
+			}
+			if allWon {
+				outcomes[position], distances[position] = LOSS, distance
+				changed++
+			}
+		}
+		if changed == 0 {
+			break
+		}
+		if distance > maxDistance {
+			return nil, fmt.Errorf("Positions take more than %d moves to decide", maxDistance)
+		}
+	}
+
+	t.values = make([]uint16, len(t.hashes))
+	counts := map[Outcome]int{}
+	for i := range t.values {
+		v := Value{Outcome: DRAW}
+		if distances[i] != undecided {
+			v = Value{Outcome: outcomes[i], Distance: distances[i]}
+		}
+		t.values[i] = v.pack()
+		counts[v.Outcome]++
+	}
+	fmt.Fprintf(log, "Solved in %v: %d won, %d lost, %d drawn\n", time.Since(start).Round(time.Millisecond), counts[WIN], counts[LOSS], counts[DRAW])
+	return t, nil
+}
+
+// enumerate calls visit with every position that has up to pieces pieces, for each player to move. The board is reused between calls.
+func enumerate(pieces int, visit func(b *game.Board, turn game.Color)) {
+	b := game.NewBoard()
+	var tiles [][2]int
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			if b.Tiles[x][y].Useable() {
+				tiles = append(tiles, [2]int{x, y})
+			}
+		}
+	}
+	var place func(tile int, remaining int)
+	place = func(tile int, remaining int) {
+		if tile == len(tiles) {
+			// Share what's left between the reserves.
+			for red := 0; red <= remaining; red++ {
+				for green := 0; green <= remaining-red; green++ {
+					b.ReservesR, b.ReservesG = red, green
+					visit(&b, game.RED)
+					visit(&b, game.GREEN)
+				}
+			}
+			return
+		}
+		x, y := tiles[tile][0], tiles[tile][1]
+		place(tile+1, remaining)
+		for height := 1; height <= 5 && height <= remaining; height++ {
+			// Each bit of colors is the color of a piece, from the bottom up.
+			for colors := 0; colors < 1<<height; colors++ {
+				for i := range b.Tiles[x][y].Pieces {
+					b.Tiles[x][y].Pieces[i] = game.Piece{}
+					if i < height {
+						b.Tiles[x][y].Pieces[i] = game.Piece{Color: game.Color(colors >> i & 1), Exists: true}
+					}
+				}
+				place(tile+1, remaining-height)
+			}
+		}
+		b.Tiles[x][y].Pieces = [5]game.Piece{}
+	}
+	place(0, pieces)
+}
@@ -0,0 +1,201 @@
+package tablebase
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// Outcome is the result of a position for the player to move, with perfect play from both sides.
+type Outcome int
+
+const (
+	LOSS Outcome = iota
+	WIN
+	DRAW
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case LOSS:
+		return "LOSS"
+	case WIN:
+		return "WIN"
+	}
+	return "DRAW"
+}
+
+// A Value is what the tablebase knows about a position.
+type Value struct {
+	Outcome Outcome
+	// Distance is the number of moves until the game ends, with the winner ending it as soon as they can and the loser holding on as long as they can. It is 0 for draws.
+	Distance int
+}
+
+func (v Value) String() string {
+	if v.Outcome == DRAW {
+		return "DRAW"
+	}
+	return fmt.Sprintf("%v in %d", v.Outcome, v.Distance)
+}
+
+// Values are packed into 16 bits, the outcome in the top 2 and the distance in the rest.
+const maxDistance = 1<<14 - 1
+
+func (v Value) pack() uint16 {
+	return uint16(v.Outcome)<<14 | uint16(v.Distance)
+}
+
+func unpack(packed uint16) Value {
+	return Value{Outcome: Outcome(packed >> 14), Distance: int(packed & maxDistance)}
+}
+
+// Tablebase holds the value of every position with up to Pieces pieces, counting the reserves as well as the board.
+// Moves never add pieces, so every move from one of these positions leads to another.
+type Tablebase struct {
+	Pieces int
+	// hashes are sorted, and values[i] is the value of the position with hashes[i].
+	hashes []uint64
+	values []uint16
+}
+
+var (
+	ErrNotTablebase = errors.New("The file is not a tablebase")
+)
+
+// Material counts the pieces on the board and in both reserves.
+func Material(b *game.Board) (pieces int) {
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			pieces += b.Tiles[x][y].Height()
+		}
+	}
+	return pieces + b.ReservesR + b.ReservesG
+}
+
+// Len returns the number of positions in the tablebase.
+func (t *Tablebase) Len() int {
+	return len(t.hashes)
+}
+
+// Probe looks up the position. It reports false if the position has too many pieces.
+func (t *Tablebase) Probe(b *game.Board, turn game.Color) (v Value, ok bool) {
+	if Material(b) > t.Pieces {
+		return v, false
+	}
+	i := t.index(b.Hash(turn))
+	if i < 0 {
+		return v, false
+	}
+	return unpack(t.values[i]), true
+}
+
+func (t *Tablebase) index(hash uint64) int {
+	i := sort.Search(len(t.hashes), func(i int) bool { return t.hashes[i] >= hash })
+	if i == len(t.hashes) || t.hashes[i] != hash {
+		return -1
+	}
+	return i
+}
+
+// BestMove picks the move that wins the quickest, or failing that draws, or failing that loses the slowest.
+func (t *Tablebase) BestMove(b *game.Board, turn game.Color) (best game.Move, v Value, ok bool) {
+	found := false
+	for _, move := range b.LegalMoves(turn) {
+		child := *b
+		child.Apply(move, turn)
+		after, ok := t.Probe(&child, turn.Opponent())
+		if !ok {
+			return best, v, false
+		}
+		// Flip the child's value round to the point of view of the player moving.
+		candidate := Value{Outcome: DRAW}
+		switch after.Outcome {
+		case LOSS:
+			candidate = Value{Outcome: WIN, Distance: after.Distance + 1}
+		case WIN:
+			candidate = Value{Outcome: LOSS, Distance: after.Distance + 1}
+		}
+		if !found || better(candidate, v) {
+			best, v, found = move, candidate, true
+		}
+	}
+	return best, v, found
+}
+
+func better(a Value, b Value) bool {
+	rank := func(v Value) int {
+		switch v.Outcome {
+		case WIN:
+			return maxDistance*2 - v.Distance
+		case DRAW:
+			return maxDistance
+		}
+		return v.Distance
+	}
+	return rank(a) > rank(b)
+}
+
+// The file is a header, then every hash, then every value, all little endian.
+var magic = [8]byte{'F', 'O', 'C', 'U', 'S', 'T', 'B', '1'}
+
+type header struct {
+	Magic     [8]byte
+	Pieces    uint32
+	Positions uint64
+}
+
+func (t *Tablebase) WriteTo(w io.Writer) (n int64, err error) {
+	bw := bufio.NewWriter(w)
+	h := header{Magic: magic, Pieces: uint32(t.Pieces), Positions: uint64(len(t.hashes))}
+	for _, data := range []any{h, t.hashes, t.values} {
+		if err = binary.Write(bw, binary.LittleEndian, data); err != nil {
+			return n, err
+		}
+		n += int64(binary.Size(data))
+	}
+	return n, bw.Flush()
+}
+
+func Read(r io.Reader) (t *Tablebase, err error) {
+	br := bufio.NewReader(r)
+	var h header
+	if err = binary.Read(br, binary.LittleEndian, &h); err != nil || h.Magic != magic {
+		return nil, ErrNotTablebase
+	}
+	t = &Tablebase{Pieces: int(h.Pieces), hashes: make([]uint64, h.Positions), values: make([]uint16, h.Positions)}
+	if err = binary.Read(br, binary.LittleEndian, t.hashes); err != nil {
+		return nil, err
+	}
+	if err = binary.Read(br, binary.LittleEndian, t.values); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func Save(path string, t *Tablebase) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err = t.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func Load(path string) (t *Tablebase, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
@@ -0,0 +1,85 @@
+package tablebase
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+func TestBuildIsConsistent(t *testing.T) {
+	tb, err := Build(2, io.Discard)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tb.Len() != 11660 {
+		t.Errorf("Expected 11660 positions, got %d", tb.Len())
+	}
+	// Every position's value should follow from the best move, which with distances that always go down means the values are right.
+	enumerate(2, func(b *game.Board, turn game.Color) {
+		v, ok := tb.Probe(b, turn)
+		if !ok {
+			t.Fatalf("Expected %q to be in the tablebase", game.FormatPosition(*b, turn))
+		}
+		_, best, ok := tb.BestMove(b, turn)
+		if !ok {
+			best = Value{Outcome: LOSS}
+		}
+		if v != best {
+			t.Errorf("Expected %q to be %v, as its best move is, got %v", game.FormatPosition(*b, turn), best, v)
+		}
+	})
+}
+
+func TestProbe(t *testing.T) {
+	tb, err := Build(2, io.Discard)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Green has nothing to move.
+	b, turn, _ := game.ParsePosition("xx4xx/xr5x/8/8/8/8/x6x/xx4xx g 0 0")
+	if v, _ := tb.Probe(&b, turn); v != (Value{Outcome: LOSS}) {
+		t.Errorf("Expected a loss, got %v", v)
+	}
+	// Red can take the green piece, leaving green nothing to move.
+	b, turn, _ = game.ParsePosition("xx4xx/xrg4x/8/8/8/8/x6x/xx4xx r 0 0")
+	move, v, _ := tb.BestMove(&b, turn)
+	if v != (Value{Outcome: WIN, Distance: 1}) || move.String() != "b2-c2" {
+		t.Errorf("Expected b2-c2 to win in 1, got %v, %v", move, v)
+	}
+	if probed, _ := tb.Probe(&b, turn); probed != v {
+		t.Errorf("Expected probing to agree with the best move, got %v", probed)
+	}
+	start := game.NewStartingBoard()
+	if _, ok := tb.Probe(&start, game.RED); ok {
+		t.Errorf("Expected the starting position to have too many pieces")
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	tb, err := Build(1, io.Discard)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var buf bytes.Buffer
+	n, err := tb.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) || n != 20+10*int64(tb.Len()) {
+		t.Fatalf("Expected %d bytes, got %d, %d, %v", 20+10*tb.Len(), n, buf.Len(), err)
+	}
+	read, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if read.Pieces != 1 || read.Len() != tb.Len() {
+		t.Errorf("Expected 1 piece and %d positions, got %d and %d", tb.Len(), read.Pieces, read.Len())
+	}
+	for i := range tb.hashes {
+		if read.hashes[i] != tb.hashes[i] || read.values[i] != tb.values[i] {
+			t.Fatalf("Expected position %d to read back the same", i)
+		}
+	}
+	if _, err := Read(bytes.NewReader([]byte("not a tablebase at all"))); err != ErrNotTablebase {
+		t.Errorf("Expected ErrNotTablebase, got %v", err)
+	}
+}
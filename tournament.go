@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/arena"
+)
+
+func tournament(args []string) (err error) {
+	config := arena.DefaultTournamentConfig()
+	flags := flag.NewFlagSet("tournament", flag.ExitOnError)
+	var specs agentList
+	flags.Var(&specs, "agent", "An agent to enter, such as random:seed=2. Give once per agent.")
+	format := flags.String("format", "roundrobin", "roundrobin or swiss")
+	flags.IntVar(&config.Rounds, "rounds", config.Rounds, "Number of rounds")
+	flags.IntVar(&config.Games, "games", config.Games, "Games per pairing each round, colours alternate")
+	flags.IntVar(&config.Concurrency, "concurrency", config.Concurrency, "Number of games to play at once")
+	flags.IntVar(&config.MaxPlies, "maxplies", config.MaxPlies, "Moves before a game is drawn")
+	flags.IntVar(&config.RandomPlies, "randomplies", config.RandomPlies, "Random moves played before the agents take over")
+	flags.Int64Var(&config.Seed, "seed", config.Seed, "Seed for the random openings")
+	flags.Float64Var(&config.Prior, "prior", config.Prior, "Virtual draws added to each rating")
+	limitFlags(flags, &config.Limits)
+	records := flags.String("records", "", "File to append every game record to")
+	crosstable := flags.String("crosstable", "", "File to write the crosstable to instead of stdout")
+	watch := flags.String("watch", "", "Address to serve the games on while they are played, such as :8080")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	config.Format, err = arena.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	var players []agent.Agent
+	for _, spec := range specs {
+		player, closeAgent, err := newAgent(spec)
+		if err != nil {
+			return err
+		}
+		defer closeAgent()
+		players = append(players, player)
+	}
+	if *records != "" {
+		f, err := os.OpenFile(*records, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		config.Records = f
+	}
+
+	ctx, err := watchGames(context.Background(), *watch)
+	if err != nil {
+		return err
+	}
+	standings, err := arena.RunTournament(ctx, players, config)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if *crosstable != "" {
+		out, err = os.Create(*crosstable)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	err = standings.WriteCrosstable(out)
+	if err != nil {
+		return fmt.Errorf("Failed to write crosstable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/headblockhead/focus-ai/eval"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// train tunes the evaluation weights to predict the results of recorded games.
+func train(args []string) (err error) {
+	config := eval.DefaultTuneConfig()
+	flags := flag.NewFlagSet("train", flag.ExitOnError)
+	records := flags.String("records", "", "File of game records to tune against")
+	weights := flags.String("weights", "", "Weights file to start from, the defaults if empty")
+	out := flags.String("out", "weights.json", "File to write the tuned weights to")
+	flags.IntVar(&config.Iterations, "iterations", config.Iterations, "Gradient descent steps")
+	flags.Float64Var(&config.LearningRate, "rate", config.LearningRate, "Learning rate")
+	flags.IntVar(&config.SkipPlies, "skipplies", config.SkipPlies, "Moves at the start of each game to leave out")
+	flags.IntVar(&config.ValidateEvery, "validate", config.ValidateEvery, "Hold back every nth game to validate with, 0 to use them all")
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *records == "" {
+		return fmt.Errorf("-records is required")
+	}
+	f, err := os.Open(*records)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	games, err := game.ReadRecords(f)
+	if err != nil {
+		return err
+	}
+	start, err := loadWeights(*weights)
+	if err != nil {
+		return err
+	}
+	tuned, err := eval.TuneRecords(start, games, config, os.Stdout)
+	if err != nil {
+		return err
+	}
+	return eval.SaveWeights(*out, tuned)
+}
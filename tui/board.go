@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// Render draws the board with row 8 at the top, like the tiles are named. Each stack is shown by the colour of its top piece and its height, so "g3" is three pieces with green on top.
+func Render(w io.Writer, b game.Board) (err error) {
+	var s strings.Builder
+	s.WriteString("   ")
+	for x := 0; x < 8; x++ {
+		fmt.Fprintf(&s, "  %c ", 'a'+x)
+	}
+	s.WriteString("\n")
+	for y := 7; y >= 0; y-- {
+		fmt.Fprintf(&s, " %d ", y+1)
+		for x := 0; x < 8; x++ {
+			fmt.Fprintf(&s, " %s ", cell(&b.Tiles[x][y]))
+		}
+		fmt.Fprintf(&s, " %d\n", y+1)
+	}
+	fmt.Fprintf(&s, "   Reserves: red %d, green %d\n", b.ReservesR, b.ReservesG)
+	_, err = io.WriteString(w, s.String())
+	return err
+}
+
+// cell is how a tile is drawn on the board, always 2 characters wide.
+func cell(tile *game.Tile) string {
+	if !tile.Useable() {
+		return "  "
+	}
+	height := tile.Height()
+	if height == 0 {
+		return " ."
+	}
+	return fmt.Sprintf("%c%d", letter(tile.Top().Color), height)
+}
+
+func letter(color game.Color) byte {
+	if color == game.RED {
+		return 'r'
+	}
+	return 'g'
+}
+
+// Stack describes every piece on a tile from the bottom up.
+func Stack(tile *game.Tile) string {
+	if !tile.Useable() {
+		return "unusable"
+	}
+	var pieces []string
+	for _, piece := range tile.Pieces {
+		if piece.Exists {
+			pieces = append(pieces, strings.ToLower(piece.Color.String()))
+		}
+	}
+	if len(pieces) == 0 {
+		return "empty"
+	}
+	return strings.Join(pieces, ", ") + " (bottom to top)"
+}
@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+var (
+	ErrAbandoned = errors.New("The game was abandoned")
+)
+
+const help = `Type a move to play it:
+  b2-d2     move the stack on b2 to d2
+  c3-e3/2   move the top 2 pieces of the stack on c3 to e3
+  *c3       place a piece from your reserve on c3
+Or a command:
+  show c3   list every piece of the stack on c3
+  moves     list your legal moves
+  board     draw the board again
+  resign    give up the game
+  help      show this again
+`
+
+// Play plays a game in a terminal from the position, returning its record once it is over.
+// Players that are nil are human, typing their moves into in. The others are agents, which play on their own.
+func Play(ctx context.Context, in io.Reader, out io.Writer, board game.Board, turn game.Color, red agent.Agent, green agent.Agent) (record game.Record, err error) {
+	record = game.Record{Red: name(red), Green: name(green), Start: board, Turn: turn}
+	lines := bufio.NewScanner(in)
+	if red == nil || green == nil {
+		fmt.Fprint(out, help)
+	}
+	for {
+		fmt.Fprintln(out)
+		Render(out, board)
+		if !board.HasLegalMove(turn) {
+			record.Result = game.WinFor(turn.Opponent())
+			record.Reason = fmt.Sprintf("%v has no legal moves", turn)
+			fmt.Fprintf(out, "%s. %v wins!\n", record.Reason, turn.Opponent())
+			return record, nil
+		}
+		player := red
+		if turn == game.GREEN {
+			player = green
+		}
+
+		var move game.Move
+		if player == nil {
+			move, err = ask(lines, out, &board, turn)
+			if errors.Is(err, errResigned) {
+				record.Result = game.WinFor(turn.Opponent())
+				record.Reason = fmt.Sprintf("%v resigned", turn)
+				fmt.Fprintf(out, "%s. %v wins!\n", record.Reason, turn.Opponent())
+				return record, nil
+			}
+			if err != nil {
+				return record, err
+			}
+		} else {
+			fmt.Fprintf(out, "%v (%s) is thinking...\n", turn, player.Name())
+			var last *agent.Info
+			thinking := agent.WithInfo(ctx, func(info agent.Info) { last = &info })
+			move, err = player.SelectMove(thinking, board, turn)
+			if err != nil {
+				return record, fmt.Errorf("%s: %w", player.Name(), err)
+			}
+			if err = board.Apply(move, turn); err != nil {
+				return record, fmt.Errorf("%s played %v: %w", player.Name(), move, err)
+			}
+			fmt.Fprintf(out, "%v plays %v", turn, move)
+			if last != nil {
+				fmt.Fprintf(out, " (depth %d, score %.2f)", last.Depth, last.Score)
+			}
+			fmt.Fprintln(out)
+		}
+		record.Moves = append(record.Moves, move)
+		turn = turn.Opponent()
+	}
+}
+
+var errResigned = errors.New("Resigned")
+
+// ask reads lines until the human types a legal move, which is played on the board.
+func ask(lines *bufio.Scanner, out io.Writer, board *game.Board, turn game.Color) (move game.Move, err error) {
+	for {
+		fmt.Fprintf(out, "%v to move> ", turn)
+		if !lines.Scan() {
+			fmt.Fprintln(out)
+			if lines.Err() != nil {
+				return move, lines.Err()
+			}
+			return move, ErrAbandoned
+		}
+		fields := strings.Fields(lines.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "help":
+			fmt.Fprint(out, help)
+		case "board":
+			Render(out, *board)
+		case "moves":
+			var moves []string
+			for _, move := range board.LegalMoves(turn) {
+				moves = append(moves, move.String())
+			}
+			fmt.Fprintln(out, strings.Join(moves, " "))
+		case "show":
+			if len(fields) != 2 {
+				fmt.Fprintln(out, "Which tile? Such as show c3")
+				continue
+			}
+			x, y, err := game.ParseTile(fields[1])
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			fmt.Fprintf(out, "%s: %s\n", fields[1], Stack(&board.Tiles[x][y]))
+		case "resign":
+			return move, errResigned
+		default:
+			move, err = game.ParseMove(fields[0])
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			// Try it on a copy, so the error from Board.Move can be shown without the board being half changed.
+			after := *board
+			if err = after.Apply(move, turn); err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			*board = after
+			return move, nil
+		}
+	}
+}
+
+func name(a agent.Agent) string {
+	if a == nil {
+		return "human"
+	}
+	return a.Name()
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+func TestRender(t *testing.T) {
+	board, _, err := game.ParsePosition("xx4xx/xr[gg]4x/8/8/8/8/x6x/xx[rrg]3xx r 1 2")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var out bytes.Buffer
+	Render(&out, board)
+	lines := strings.Split(out.String(), "\n")
+	if lines[0] != "     a   b   c   d   e   f   g   h " {
+		t.Errorf("Expected the columns to be labelled, got %q", lines[0])
+	}
+	// Row 8 is drawn first, just under the labels.
+	if lines[1] != " 8          g3   .   .   .          8" {
+		t.Errorf("Expected row 8, got %q", lines[1])
+	}
+	if lines[7] != " 2      r1  g2   .   .   .   .      2" {
+		t.Errorf("Expected row 2, got %q", lines[7])
+	}
+	if !strings.Contains(out.String(), "Reserves: red 1, green 2") {
+		t.Errorf("Expected the reserves, got %q", out.String())
+	}
+	if stack := Stack(&board.Tiles[2][7]); stack != "red, red, green (bottom to top)" {
+		t.Errorf("Expected the whole stack, got %q", stack)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	// Red is human, with only one piece that can go anywhere useful.
+	board, turn, err := game.ParsePosition("xx4xx/xrg4x/8/8/8/8/x6x/xx4xx r 0 0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	in := strings.NewReader("show c2\nc2-c3\nb2-b4\nb2-c2\n")
+	var out bytes.Buffer
+	record, err := Play(context.Background(), in, &out, board, turn, nil, agent.NewRandom(1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if record.Result != game.RED_WON || len(record.Moves) != 1 || record.Red != "human" || record.Green != "random" {
+		t.Errorf("Expected the human to win with one move, got %+v", record)
+	}
+	for _, expected := range []string{"c2: green (bottom to top)", game.ErrWrongColor.Error(), "GREEN has no legal moves. RED wins!"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in the output", expected)
+		}
+	}
+}
+
+func TestPlayAbandoned(t *testing.T) {
+	var out bytes.Buffer
+	record, err := Play(context.Background(), strings.NewReader("moves\n"), &out, game.NewStartingBoard(), game.RED, nil, nil)
+	if err != ErrAbandoned {
+		t.Errorf("Expected ErrAbandoned, got %v", err)
+	}
+	if record.Result != game.UNDECIDED {
+		t.Errorf("Expected no result, got %v", record.Result)
+	}
+	if !strings.Contains(out.String(), "b2-c2") {
+		t.Errorf("Expected the legal moves to be listed, got %q", out.String())
+	}
+}
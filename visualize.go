@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// visualize opens the visualizer window on a position, for people to play with the mouse or to watch agents.
+func visualize(args []string) (err error) {
+	flags := flag.NewFlagSet("visualize", flag.ExitOnError)
+	position := flags.String("position", "start", "Position to show")
+	red := flags.String("red", "human", "Who plays red, human or an agent such as alphabeta:depth=4")
+	green := flags.String("green", "human", "Who plays green, human or an agent")
+	options := windowFlags(flags)
+	if err = parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	board, turn, err := game.ParsePosition(*position)
+	if err != nil {
+		return err
+	}
+	redPlayer, closeRed, err := newPlayer(*red)
+	if err != nil {
+		return err
+	}
+	defer closeRed()
+	greenPlayer, closeGreen, err := newPlayer(*green)
+	if err != nil {
+		return err
+	}
+	defer closeGreen()
+	return openWindow(board, turn, redPlayer, greenPlayer, *options)
+}
+
+// windowOptions are how the visualizer shows games, the same for every command that opens it.
+type windowOptions struct {
+	speed float64
+	// heatmap is an agent spec for what to show the policy and value of.
+	heatmap string
+}
+
+func windowFlags(flags *flag.FlagSet) *windowOptions {
+	options := &windowOptions{}
+	flags.Float64Var(&options.speed, "speed", 1, "How fast moves are animated in the visualizer, 0 to show them straight away")
+	flags.StringVar(&options.heatmap, "heatmap", "mcts:iterations=1000", "Agent whose policy and value the visualizer shows with P, which must be mcts, or empty for none")
+	return options
+}
@@ -0,0 +1,232 @@
+package visualizer
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/solarlune/tetra3d"
+)
+
+const (
+	// How long the pieces take to fly to their new tile, and then to fall off the bottom of a stack that is too tall, at an AnimationSpeed of 1.
+	flyTime  = 0.6
+	fallTime = 0.4
+	// maxReservesShown is how many reserve pieces are drawn beside the board for each player.
+	maxReservesShown = 18
+)
+
+// slotPosition is where the model for piece k of the stack on tile x, y goes. Tiles off the board are beside it.
+func slotPosition(x int, y int, k int) tetra3d.Vector {
+	// The piece models are upside down, so they hang from the corner of the tile they are put on.
+	cx, cz := tileCentre(x, y)
+	return tetra3d.NewVector(cx-tileSize/2, boardHeight+0.1+float64(k)*pieceHeight, cz+tileSize/2)
+}
+
+// reservePosition is where the nth reserve piece of a colour is drawn, in piles of 5 on that player's side of the board.
+func reservePosition(color game.Color, n int) tetra3d.Vector {
+	if color == game.RED {
+		return slotPosition(-2, 2+n/5, n%5)
+	}
+	return slotPosition(9, 5-n/5, n%5)
+}
+
+// discardPosition is where the nth piece knocked out of the game by a move lands, off the end of the board, before it disappears.
+func discardPosition(n int) tetra3d.Vector {
+	return slotPosition(3+n%2, -2, n/2).Sub(tetra3d.NewVector(0, 1, 0))
+}
+
+// arc moves from one point to another as t goes from 0 to 1, rising by height in the middle.
+func arc(from tetra3d.Vector, to tetra3d.Vector, height float64, t float64) tetra3d.Vector {
+	t = t * t * (3 - 2*t)
+	p := from.Add(to.Sub(from).Scale(t))
+	p.Y += 4 * height * t * (1 - t)
+	return p
+}
+
+// newSpareModels makes the pieces that fly about during animations, and the piles of reserve pieces.
+func (vis *Visualizer) newSpareModels(templates [2]*tetra3d.Model) {
+	for color, template := range templates {
+		for i := range vis.Flying[color] {
+			piece := template.Clone().(*tetra3d.Model)
+			piece.SetVisible(false, true)
+			vis.Flying[color][i] = piece
+			vis.Scene.Root.AddChildren(piece)
+		}
+		for n := range vis.ReservePiles[color] {
+			piece := template.Clone().(*tetra3d.Model)
+			piece.SetWorldPositionVec(reservePosition(game.Color(color), n))
+			piece.SetVisible(false, true)
+			vis.ReservePiles[color][n] = piece
+			vis.Scene.Root.AddChildren(piece)
+		}
+	}
+}
+
+// show sets the piece models on the board and in the reserve piles to match a board.
+func (vis *Visualizer) show(board *game.Board) {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			tile := board.Tiles[i][j]
+			for k := 0; k < len(tile.Pieces); k++ {
+				piece := tile.Pieces[k]
+				vis.RedPieces[i][j][k].SetVisible(piece.Exists && piece.Color == game.RED, true)
+				vis.GreenPieces[i][j][k].SetVisible(piece.Exists && piece.Color == game.GREEN, true)
+			}
+		}
+	}
+	for color, pile := range vis.ReservePiles {
+		reserves := *board.GetReserves(game.Color(color))
+		for n, piece := range pile {
+			piece.SetVisible(n < reserves, true)
+		}
+	}
+}
+
+// A flight is a piece moving through the air.
+type flight struct {
+	piece    game.Piece
+	from, to tetra3d.Vector
+	height   float64
+}
+
+// An animation shows a move being played.
+type animation struct {
+	// During is shown under the flying pieces: the board once the pieces have been lifted, and then with the landing stack taken away as it falls.
+	during [2]game.Board
+	// flights are the pieces in the air for each part of the animation.
+	flights [2][]flight
+	// elapsed is how many seconds into the animation it is.
+	elapsed float64
+}
+
+// newAnimation works out how the pieces move for a move played from before. It isn't ok if the pieces wouldn't end up like after, which shouldn't happen, and means the move is best shown without animating.
+func newAnimation(before game.Board, after game.Board, move game.Move, turn game.Color) (a *animation, ok bool) {
+	a = &animation{}
+	lifted := before
+	var flying []flight
+	if move.FromReserve {
+		reserves := lifted.GetReserves(turn)
+		*reserves--
+		flying = append(flying, flight{piece: game.Piece{Color: turn, Exists: true}, from: reservePosition(turn, *reserves)})
+	} else {
+		// Board.Move takes the pieces off the top one at a time, so the top piece lands first.
+		tile := &lifted.Tiles[move.X][move.Y]
+		height := tile.Height()
+		if move.Pieces > height {
+			return nil, false
+		}
+		for k := height - 1; k >= height-move.Pieces; k-- {
+			flying = append(flying, flight{piece: tile.Pieces[k], from: slotPosition(move.X, move.Y, k)})
+			tile.Pieces[k].Exists = false
+		}
+	}
+	a.during[0] = lifted
+
+	// The pieces land on top of the stack, even if it gets too tall for a moment.
+	x, y := move.Destination()
+	if x < 0 || x > 7 || y < 0 || y > 7 {
+		return nil, false
+	}
+	var stack []game.Piece
+	for _, piece := range lifted.Tiles[x][y].Pieces {
+		if piece.Exists {
+			stack = append(stack, piece)
+		}
+	}
+	for i := range flying {
+		flying[i].to = slotPosition(x, y, len(stack))
+		flying[i].height = 1.5 + 0.3*float64(abs(x-move.X)+abs(y-move.Y))
+		stack = append(stack, flying[i].piece)
+	}
+	a.flights[0] = flying
+
+	// Then the bottom of a stack that is too tall falls off, to the mover's reserve if it is theirs and out of the game if not.
+	overflow := len(stack) - len(game.Tile{}.Pieces)
+	if overflow < 0 {
+		overflow = 0
+	}
+	expected := lifted
+	falling := lifted
+	falling.Tiles[x][y].Pieces = [5]game.Piece{}
+	discarded := 0
+	for k, piece := range stack {
+		f := flight{piece: piece, from: slotPosition(x, y, k)}
+		switch {
+		case k >= overflow:
+			f.to = slotPosition(x, y, k-overflow)
+			expected.Tiles[x][y].Pieces[k-overflow] = piece
+		case piece.Color == turn:
+			reserves := expected.GetReserves(turn)
+			f.to = reservePosition(turn, *reserves)
+			f.height = 1
+			*reserves++
+		default:
+			f.to = discardPosition(discarded)
+			f.height = 1
+			discarded++
+		}
+		a.flights[1] = append(a.flights[1], f)
+	}
+	a.during[1] = falling
+	if expected != after {
+		return nil, false
+	}
+	// Without anything falling off there is nothing more to show.
+	if overflow == 0 {
+		a.flights[1] = nil
+	}
+	return a, true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// animate moves the animations on by a frame, returning whether there are any left to show.
+func (vis *Visualizer) animate() bool {
+	if vis.AnimationSpeed <= 0 {
+		vis.animations = nil
+	}
+	if len(vis.animations) == 0 {
+		for _, pool := range vis.Flying {
+			for _, piece := range pool {
+				piece.SetVisible(false, true)
+			}
+		}
+		return false
+	}
+	a := vis.animations[0]
+	a.elapsed += vis.AnimationSpeed / float64(ebiten.TPS())
+	part, t := 0, a.elapsed/flyTime
+	if t > 1 {
+		part, t = 1, (a.elapsed-flyTime)/fallTime
+	}
+	if t > 1 || a.flights[part] == nil {
+		// Done with this one, the next starts on the next frame.
+		vis.animations = vis.animations[1:]
+		if len(vis.animations) == 0 {
+			vis.synced = false
+		}
+		return true
+	}
+	vis.show(&a.during[part])
+	used := [2]int{}
+	for _, f := range a.flights[part] {
+		color := f.piece.Color
+		if used[color] == len(vis.Flying[color]) {
+			continue
+		}
+		piece := vis.Flying[color][used[color]]
+		used[color]++
+		piece.SetWorldPositionVec(arc(f.from, f.to, f.height, t))
+		piece.SetVisible(true, true)
+	}
+	for color, pool := range vis.Flying {
+		for _, piece := range pool[used[color]:] {
+			piece.SetVisible(false, true)
+		}
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+package visualizer
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/solarlune/tetra3d"
+)
+
+// An Orbit is where the camera is, as a point it looks at and how far around, above and away from it the camera is.
+type Orbit struct {
+	Target tetra3d.Vector
+	// Yaw is the angle around the board in radians, 0 looking from the side of the 8th row. Pitch is the angle above the board.
+	Yaw, Pitch float64
+	Distance   float64
+}
+
+// Position is where the camera is in the scene.
+func (o Orbit) Position() tetra3d.Vector {
+	return o.Target.Add(tetra3d.NewVector(
+		math.Cos(o.Pitch)*math.Sin(o.Yaw),
+		math.Sin(o.Pitch),
+		math.Cos(o.Pitch)*math.Cos(o.Yaw),
+	).Scale(o.Distance))
+}
+
+// The camera can't go under the board, quite straight down, or too close or far away to see it.
+const (
+	minPitch    = 5 * math.Pi / 180
+	maxPitch    = 89.9 * math.Pi / 180
+	minDistance = 10
+	maxDistance = 150
+	maxPan      = 16
+)
+
+// clamped keeps the camera somewhere the board can be seen from.
+func (o Orbit) clamped() Orbit {
+	o.Pitch = math.Max(minPitch, math.Min(maxPitch, o.Pitch))
+	o.Distance = math.Max(minDistance, math.Min(maxDistance, o.Distance))
+	o.Target.X = math.Max(-maxPan, math.Min(maxPan, o.Target.X))
+	o.Target.Z = math.Max(-maxPan, math.Min(maxPan, o.Target.Z))
+	return o
+}
+
+// A View is a preset camera position.
+type View int
+
+const (
+	// VIEW_SCENE is where the camera is in the scene file.
+	VIEW_SCENE View = iota
+	VIEW_TOP
+	// VIEW_RED and VIEW_GREEN look from behind each player's reserves.
+	VIEW_RED
+	VIEW_GREEN
+	VIEW_ISOMETRIC
+)
+
+// SetView moves the camera to a preset view.
+func (vis *Visualizer) SetView(view View) {
+	switch view {
+	case VIEW_SCENE:
+		vis.Orbit = vis.sceneOrbit
+	case VIEW_TOP:
+		vis.Orbit = Orbit{Yaw: 0, Pitch: maxPitch, Distance: 55}
+	case VIEW_RED:
+		vis.Orbit = Orbit{Yaw: -math.Pi / 2, Pitch: 45 * math.Pi / 180, Distance: 55}
+	case VIEW_GREEN:
+		vis.Orbit = Orbit{Yaw: math.Pi / 2, Pitch: 45 * math.Pi / 180, Distance: 55}
+	case VIEW_ISOMETRIC:
+		vis.Orbit = Orbit{Yaw: math.Pi / 4, Pitch: math.Atan(1 / math.Sqrt2), Distance: 60}
+	}
+	vis.placeCamera()
+}
+
+// orbitFrom works out the orbit of a camera placed in the scene, taking it to be looking at the board.
+func orbitFrom(camera *tetra3d.Camera) Orbit {
+	position := camera.WorldPosition()
+	// Cameras look down -Z.
+	forward := camera.WorldRotation().Forward().Invert()
+	if forward.Y >= 0 {
+		// Looking away from the board, so look at the middle instead.
+		forward = tetra3d.NewVector(0, 0, 0).Sub(position).Unit()
+	}
+	target := position.Add(forward.Scale((boardHeight - position.Y) / forward.Y))
+	offset := position.Sub(target)
+	distance := offset.Magnitude()
+	return Orbit{
+		Target:   target,
+		Yaw:      math.Atan2(offset.X, offset.Z),
+		Pitch:    math.Asin(offset.Y / distance),
+		Distance: distance,
+	}.clamped()
+}
+
+// placeCamera puts the camera where the orbit says.
+func (vis *Visualizer) placeCamera() {
+	vis.Orbit = vis.Orbit.clamped()
+	camera := vis.Camera()
+	position := vis.Orbit.Position()
+	camera.SetWorldPositionVec(position)
+	// The look at matrix points +Z at the target, and cameras look down -Z, so look from the target to the camera.
+	camera.SetWorldRotation(tetra3d.NewLookAtMatrix(vis.Orbit.Target, position, tetra3d.WorldUp))
+}
+
+// handleCamera moves the camera around the board.
+//
+// Dragging with the middle mouse button or the arrow keys orbit, holding shift while dragging or WASD pan, and the mouse wheel (when it isn't choosing how many pieces to lift), +/- or page up/down zoom.
+// F1 looks from the top, F2 from red's side, F3 from green's side, F4 isometrically, and Home goes back to the start.
+func (vis *Visualizer) handleCamera() {
+	for key, view := range map[ebiten.Key]View{
+		ebiten.KeyF1:   VIEW_TOP,
+		ebiten.KeyF2:   VIEW_RED,
+		ebiten.KeyF3:   VIEW_GREEN,
+		ebiten.KeyF4:   VIEW_ISOMETRIC,
+		ebiten.KeyHome: VIEW_SCENE,
+	} {
+		if inpututil.IsKeyJustPressed(key) {
+			vis.SetView(view)
+		}
+	}
+
+	// Per frame, turning about 90 degrees and moving about a tile a second.
+	turn := 1.5 / float64(ebiten.TPS())
+	step := 0.15 * vis.Orbit.Distance / float64(ebiten.TPS())
+	var yaw, pitch, right, forward, zoom float64
+	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+		yaw -= turn
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+		yaw += turn
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
+		pitch += turn
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
+		pitch -= turn
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyA) {
+		right -= step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyD) {
+		right += step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyW) {
+		forward += step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyS) {
+		forward -= step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyEqual) || ebiten.IsKeyPressed(ebiten.KeyKPAdd) || ebiten.IsKeyPressed(ebiten.KeyPageUp) {
+		zoom -= turn
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyMinus) || ebiten.IsKeyPressed(ebiten.KeyKPSubtract) || ebiten.IsKeyPressed(ebiten.KeyPageDown) {
+		zoom += turn
+	}
+	// The wheel picks how many pieces to lift while a stack is picked up.
+	if _, dy := ebiten.Wheel(); !vis.Selection.Active || vis.Selection.FromReserve {
+		zoom -= dy * 0.1
+	}
+
+	x, y := ebiten.CursorPosition()
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle) && !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonMiddle) {
+		// Dragging across the whole height of the window turns the camera half way round, or moves it as far as it is from the board.
+		dx := float64(x-vis.cursor[0]) / float64(vis.Height)
+		dy := float64(y-vis.cursor[1]) / float64(vis.Height)
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			right -= dx * vis.Orbit.Distance
+			forward += dy * vis.Orbit.Distance
+		} else {
+			yaw -= dx * math.Pi
+			pitch += dy * math.Pi
+		}
+	}
+	vis.cursor = [2]int{x, y}
+
+	if yaw == 0 && pitch == 0 && right == 0 && forward == 0 && zoom == 0 {
+		return
+	}
+	vis.Orbit.Yaw += yaw
+	vis.Orbit.Pitch += pitch
+	vis.Orbit.Distance *= math.Exp(zoom)
+	// Panning is along the board as seen from the camera.
+	sin, cos := math.Sincos(vis.Orbit.Yaw)
+	vis.Orbit.Target = vis.Orbit.Target.Add(tetra3d.NewVector(cos*right-sin*forward, 0, -sin*right-cos*forward))
+	vis.placeCamera()
+}
@@ -2,6 +2,7 @@ module github.com/headblockhead/focus-ai/visualizer
 
 require (
 	github.com/hajimehoshi/ebiten/v2 v2.5.0
+	github.com/headblockhead/focus-ai/game v0.0.0
 	github.com/solarlune/tetra3d v0.14.0
 )
 
@@ -10,14 +11,13 @@ replace github.com/headblockhead/focus-ai/game v0.0.0 => ../game
 require (
 	github.com/ebitengine/purego v0.4.0-alpha.1.0.20230327173358-ebd8567e49db // indirect
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20221017161538-93cebf72946b // indirect
-	github.com/headblockhead/focus-ai/game v0.0.0 // indirect
 	github.com/jezek/xgb v1.1.0 // indirect
 	github.com/qmuntal/gltf v0.23.1 // indirect
 	golang.org/x/exp/shiny v0.0.0-20230321023759-10a507213a29 // indirect
 	golang.org/x/image v0.6.0 // indirect
 	golang.org/x/mobile v0.0.0-20230301163155-e0f57694e12c // indirect
 	golang.org/x/sync v0.1.0 // indirect
-	golang.org/x/sys v0.6.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
 )
 
 go 1.20
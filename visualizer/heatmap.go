@@ -0,0 +1,230 @@
+package visualizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/solarlune/tetra3d"
+)
+
+// An Analysis is what a model thinks of a position.
+type Analysis struct {
+	Moves []game.Move
+	// Policy is how likely each of Moves is to be played, adding up to 1.
+	Policy []float64
+	// Value is how good the position is for the player to move, from -1 for a loss to 1 for a win.
+	Value float64
+}
+
+// An Analyst analyses positions for the heatmap, such as with a network's policy and value heads.
+// It is run away from the window, so it can take its time, and should stop soon after ctx is cancelled.
+type Analyst interface {
+	Analyse(ctx context.Context, board game.Board, turn game.Color) (Analysis, error)
+}
+
+type HeatmapMode int
+
+const (
+	HEATMAP_OFF HeatmapMode = iota
+	// HEATMAP_SOURCE adds up the policy of the moves from each tile, and HEATMAP_DESTINATION of the moves to it.
+	HEATMAP_SOURCE
+	HEATMAP_DESTINATION
+	HEATMAP_MODES
+)
+
+func (m HeatmapMode) String() string {
+	switch m {
+	case HEATMAP_SOURCE:
+		return "moves from each tile"
+	case HEATMAP_DESTINATION:
+		return "moves to each tile"
+	}
+	return "off"
+}
+
+// Heat adds up the policy of an analysis by tile. Placements from the reserve don't come from a tile, so they are added up separately.
+func Heat(analysis Analysis, mode HeatmapMode) (heat [8][8]float64, reserve float64) {
+	for i, move := range analysis.Moves {
+		if i >= len(analysis.Policy) {
+			break
+		}
+		p := analysis.Policy[i]
+		switch {
+		case mode == HEATMAP_SOURCE && move.FromReserve:
+			reserve += p
+		case mode == HEATMAP_SOURCE:
+			heat[move.X][move.Y] += p
+		case mode == HEATMAP_DESTINATION:
+			if x, y := move.Destination(); x >= 0 && x < 8 && y >= 0 && y < 8 {
+				heat[x][y] += p
+			}
+		}
+	}
+	return heat, reserve
+}
+
+type position struct {
+	board game.Board
+	turn  game.Color
+}
+
+type analysed struct {
+	position
+	analysis Analysis
+	err      error
+}
+
+// newHeat makes a see-through model lying on each tile, under the highlights, to colour by the heatmap.
+func (vis *Visualizer) newHeat() {
+	mesh := tetra3d.NewPlaneMesh(2, 2)
+	material := mesh.MeshParts[0].Material
+	material.Shadeless = true
+	material.BackfaceCulling = false
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			heat := tetra3d.NewModel(mesh, "Heat")
+			x, z := tileCentre(i, j)
+			heat.SetWorldPosition(x, boardHeight+0.005, z)
+			heat.SetLocalScale(tileSize*0.95, 1, tileSize*0.95)
+			heat.SetVisible(false, true)
+			vis.Heat[i][j] = heat
+			vis.Scene.Root.AddChildren(heat)
+		}
+	}
+	vis.analysis = nil
+	vis.requested = nil
+}
+
+// analyse has the analyst start on the position shown, unless it already has, cancelling anything it was working on.
+func (vis *Visualizer) analyse() {
+	p := position{board: *vis.Board, turn: vis.Turn}
+	if vis.requested != nil && *vis.requested == p {
+		return
+	}
+	vis.stopAnalysing()
+	vis.requested = &p
+	ctx, cancel := context.WithCancel(context.Background())
+	vis.cancelAnalysis = cancel
+	analyst, results := vis.Analyst, vis.analyses
+	go func() {
+		analysis, err := analyst.Analyse(ctx, p.board, p.turn)
+		// Results that aren't picked up in time are for a position that has gone.
+		select {
+		case results <- analysed{position: p, analysis: analysis, err: err}:
+		default:
+		}
+	}()
+}
+
+func (vis *Visualizer) stopAnalysing() {
+	if vis.cancelAnalysis != nil {
+		vis.cancelAnalysis()
+		vis.cancelAnalysis = nil
+	}
+	vis.requested = nil
+}
+
+// updateHeatmap colours the tiles by the policy of the position shown, asking the analyst for it when it changes.
+func (vis *Visualizer) updateHeatmap() {
+	for len(vis.analyses) > 0 {
+		a := <-vis.analyses
+		// Being cancelled isn't worth mentioning, it only happens once the result isn't wanted.
+		if a.err != nil && !errors.Is(a.err, context.Canceled) && a.position == (position{board: *vis.Board, turn: vis.Turn}) {
+			vis.Message = fmt.Sprintf("Heatmap: %v", a.err)
+		}
+		if a.err == nil {
+			vis.analysis = &a
+		}
+	}
+	current := position{board: *vis.Board, turn: vis.Turn}
+	on := vis.Heatmap != HEATMAP_OFF && vis.Analyst != nil && vis.Board.HasLegalMove(vis.Turn)
+	if !on {
+		vis.stopAnalysing()
+	} else if vis.analysis == nil || vis.analysis.position != current {
+		vis.analyse()
+	}
+
+	var heat [8][8]float64
+	showing := on && !vis.highlighting && vis.analysis != nil && vis.analysis.position == current
+	if showing {
+		heat, _ = Heat(vis.analysis.analysis, vis.Heatmap)
+	}
+	hottest := 0.0
+	for i := range heat {
+		for j := range heat[i] {
+			hottest = math.Max(hottest, heat[i][j])
+		}
+	}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			model := vis.Heat[i][j]
+			if heat[i][j] == 0 {
+				model.SetVisible(false, true)
+				continue
+			}
+			// From a faint blue for the least likely to a strong red for the most.
+			t := heat[i][j] / hottest
+			model.Color = tetra3d.NewColor(0.1+0.9*float32(t), 0.3-0.1*float32(t), 1-0.9*float32(t), 0.25+0.5*float32(t))
+			model.SetVisible(true, true)
+		}
+	}
+}
+
+// drawHeatmap writes how likely each tile is on it, and draws a bar of how good the position is for each player down the left.
+func (vis *Visualizer) drawHeatmap(screen *ebiten.Image) {
+	if vis.Heatmap == HEATMAP_OFF || vis.Analyst == nil {
+		return
+	}
+	current := position{board: *vis.Board, turn: vis.Turn}
+	if vis.analysis == nil || vis.analysis.position != current {
+		if vis.Board.HasLegalMove(vis.Turn) {
+			vis.drawText(screen, fmt.Sprintf("Heatmap of %v: thinking...", vis.Heatmap), 16, textHeight-100, tetra3d.NewColor(1, 1, 1, 1))
+		}
+		return
+	}
+	analysis := vis.analysis.analysis
+	scale := float64(vis.Height) / textHeight
+	camera := vis.Camera()
+	if !vis.highlighting {
+		heat, reserve := Heat(analysis, vis.Heatmap)
+		for i := range heat {
+			for j := range heat[i] {
+				// Too unlikely to be worth reading.
+				if heat[i][j] < 0.005 {
+					continue
+				}
+				x, z := tileCentre(i, j)
+				at := camera.WorldToScreen(tetra3d.NewVector(x, boardHeight, z))
+				label := fmt.Sprintf("%.0f%%", heat[i][j]*100)
+				// Centred on the tile, allowing for the text being drawn under where it is put.
+				vis.drawText(screen, label, at.X/scale-float64(len(label)*charWidth)/2-4, at.Y/scale-18, tetra3d.NewColor(1, 1, 1, 1))
+			}
+		}
+		label := fmt.Sprintf("Heatmap of %v", vis.Heatmap)
+		if vis.Heatmap == HEATMAP_SOURCE {
+			label += fmt.Sprintf(", %.0f%% placing from the reserve", reserve*100)
+		}
+		vis.drawText(screen, label, 16, textHeight-100, tetra3d.NewColor(1, 1, 1, 1))
+	}
+
+	// The value bar is red from the bottom and green from the top, meeting where the position is even for red at the middle.
+	red := analysis.Value
+	if vis.Turn == game.GREEN {
+		red = -red
+	}
+	const barX, barY, barWidth, barHeight = 16, 120, 10, 300
+	redHeight := barHeight * (red + 1) / 2
+	rect := func(x, y, w, h float64, c color.Color) {
+		vector.DrawFilledRect(screen, float32(x*scale), float32(y*scale), float32(w*scale), float32(h*scale), c, false)
+	}
+	rect(barX, barY, barWidth, barHeight-redHeight, color.RGBA{R: 40, G: 190, B: 60, A: 220})
+	rect(barX, barY+barHeight-redHeight, barWidth, redHeight, color.RGBA{R: 210, G: 40, B: 40, A: 220})
+	rect(barX-2, barY+barHeight/2, barWidth+4, 1, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	vis.drawText(screen, fmt.Sprintf("Value %+.2f for %v", analysis.Value, vis.Turn), barX-4, barY+barHeight, tetra3d.NewColor(1, 1, 1, 1))
+}
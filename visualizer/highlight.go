@@ -0,0 +1,113 @@
+package visualizer
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/solarlune/tetra3d"
+)
+
+// pieceColors colour a destination by how many pieces reach it, from 1 to 5.
+var pieceColors = [5]tetra3d.Color{
+	tetra3d.NewColor(0.2, 0.5, 1, 1),
+	tetra3d.NewColor(0.2, 0.9, 0.9, 1),
+	tetra3d.NewColor(1, 0.9, 0.2, 1),
+	tetra3d.NewColor(1, 0.5, 0.1, 1),
+	tetra3d.NewColor(1, 0.3, 0.8, 1),
+}
+
+const highlightLegend = "Tiles you can reach are coloured by how many pieces get there: 1 blue, 2 cyan, 3 yellow, 4 orange, 5 pink"
+
+// newHighlights makes a flat, see-through model lying on each tile, hidden until there is something to show.
+func (vis *Visualizer) newHighlights() {
+	mesh := tetra3d.NewPlaneMesh(2, 2)
+	material := mesh.MeshParts[0].Material
+	material.Shadeless = true
+	material.BackfaceCulling = false
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			highlight := tetra3d.NewModel(mesh, "Highlight")
+			x, z := tileCentre(i, j)
+			// Just above the tile, and a little smaller than the tile so the tiles stay apart.
+			highlight.SetWorldPosition(x, boardHeight+0.01, z)
+			highlight.SetLocalScale(tileSize*0.9, 1, tileSize*0.9)
+			highlight.SetVisible(false, true)
+			vis.Highlights[i][j] = highlight
+			vis.Scene.Root.AddChildren(highlight)
+		}
+	}
+}
+
+// Destinations returns, for every tile, which numbers of pieces can be moved there from the selection by the player to move.
+// Bit n-1 is set if n pieces can get there, so 0 means the tile can't be reached at all.
+func Destinations(board game.Board, turn game.Color, from Selection) (reach [8][8]uint8) {
+	for _, move := range board.LegalMoves(turn) {
+		if move.FromReserve != from.FromReserve || (!move.FromReserve && (move.X != from.X || move.Y != from.Y)) {
+			continue
+		}
+		x, y := move.Destination()
+		reach[x][y] |= 1 << (move.Pieces - 1)
+	}
+	return reach
+}
+
+// hovered returns what would be picked up by clicking under the mouse, so its moves can be shown before it is.
+func (vis *Visualizer) hovered() (from Selection, ok bool) {
+	if vis.Selection.Active {
+		return vis.Selection, true
+	}
+	if vis.Over || !vis.Human[vis.Turn] {
+		return from, false
+	}
+	x, y, ok := vis.Pick(ebiten.CursorPosition())
+	if !ok {
+		return from, false
+	}
+	height := vis.Board.Tiles[x][y].Height()
+	return Selection{Active: true, X: x, Y: y, Pieces: height}, height > 0
+}
+
+// updateHighlights colours the tiles the hovered or selected stack can move to, and dims the rest.
+func (vis *Visualizer) updateHighlights() {
+	from, ok := vis.hovered()
+	var reach [8][8]uint8
+	if ok {
+		reach = Destinations(*vis.Board, vis.Turn, from)
+	}
+	// A stack that can't go anywhere, such as the opponent's, isn't worth dimming the board for.
+	reachable := false
+	for i := range reach {
+		for j := range reach[i] {
+			reachable = reachable || reach[i][j] != 0
+		}
+	}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			highlight := vis.Highlights[i][j]
+			tile := &vis.Board.Tiles[i][j]
+			switch {
+			case !reachable || !tile.Useable():
+				highlight.SetVisible(false, true)
+			case reach[i][j] == 0:
+				// Dim the tiles that can't be reached, and lighten the one the pieces are coming from.
+				highlight.SetVisible(true, true)
+				highlight.Color = tetra3d.NewColor(0, 0, 0, 0.45)
+				if !from.FromReserve && i == from.X && j == from.Y {
+					highlight.Color = tetra3d.NewColor(1, 1, 1, 0.35)
+				}
+			case reach[i][j]&(1<<(from.Pieces-1)) != 0:
+				// Where the pieces being lifted can go.
+				highlight.SetVisible(true, true)
+				highlight.Color = pieceColors[from.Pieces-1].MultiplyRGBA(1, 1, 1, 0.6)
+			default:
+				// Where a different number of pieces could go, in the colour of the fewest.
+				fewest := 0
+				for reach[i][j]&(1<<fewest) == 0 {
+					fewest++
+				}
+				highlight.SetVisible(true, true)
+				highlight.Color = pieceColors[fewest].MultiplyRGBA(1, 1, 1, 0.3)
+			}
+		}
+	}
+	vis.highlighting = reachable
+}
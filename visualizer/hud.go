@@ -0,0 +1,78 @@
+package visualizer
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/solarlune/tetra3d"
+)
+
+// An Evaluation is what an agent thinks of the position while it searches, for the HUD.
+type Evaluation struct {
+	Agent string
+	Turn  game.Color
+	Depth int
+	// Score is from the point of view of the player to move, in the agent's own units.
+	Score float64
+}
+
+// Text is drawn as if the screen were textHeight high, so it is the same size on every window.
+const (
+	textHeight = 540
+	lineHeight = 20
+	// The basic font is 7 pixels wide.
+	charWidth = 7
+)
+
+// drawText writes on the screen, with x and y measured as if the screen were textHeight high.
+func (vis *Visualizer) drawText(screen *ebiten.Image, text string, x float64, y float64, c tetra3d.Color) {
+	// DebugDrawText scales where the text goes along with the text.
+	vis.Camera().DebugDrawText(screen, text, x, y, float64(vis.Height)/textHeight, c)
+}
+
+// hudLines are the lines of the HUD: the state of the game, the reserves, the last move and how the agent thinking sees it.
+func (vis *Visualizer) hudLines() (lines []string) {
+	switch {
+	case vis.Over && vis.Replay != nil:
+		record := vis.Replay.Record
+		lines = append(lines, fmt.Sprintf("Game over after %d moves, %v", len(vis.History), record.Result))
+		if record.Reason != "" {
+			lines = append(lines, record.Reason)
+		}
+	case vis.Over:
+		// The game ends when the player to move can't.
+		lines = append(lines, fmt.Sprintf("Game over after %d moves, %v wins", len(vis.History), vis.Turn.Opponent()))
+	default:
+		lines = append(lines, fmt.Sprintf("Move %d, %v to move", len(vis.History)+1, vis.Turn))
+	}
+	lines = append(lines, fmt.Sprintf("Reserves: red %d, green %d", vis.Board.ReservesR, vis.Board.ReservesG))
+	if len(vis.History) > 0 {
+		lines = append(lines, fmt.Sprintf("Last move: %v", vis.History[len(vis.History)-1]))
+	}
+	if e := vis.Evaluation; e.Agent != "" {
+		lines = append(lines, fmt.Sprintf("%s for %v: %+.2f at depth %d", e.Agent, e.Turn, e.Score, e.Depth))
+	}
+	return lines
+}
+
+// drawHUD draws the HUD in a box in the top right of the screen.
+func (vis *Visualizer) drawHUD(screen *ebiten.Image) {
+	lines := vis.hudLines()
+	width := 0
+	for _, line := range lines {
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+	scale := float64(vis.Height) / textHeight
+	boxWidth := float64(width*charWidth + 16)
+	x := float64(vis.Width)/scale - boxWidth - 16
+	// The text sits a little under where it is drawn.
+	vector.DrawFilledRect(screen, float32(x*scale), float32(20*scale), float32(boxWidth*scale), float32((float64(len(lines)*lineHeight)+12)*scale), color.RGBA{A: 160}, false)
+	for i, line := range lines {
+		vis.drawText(screen, line, x+4, float64(16+i*lineHeight), tetra3d.NewColor(1, 1, 1, 1))
+	}
+}
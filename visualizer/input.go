@@ -0,0 +1,202 @@
+package visualizer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/solarlune/tetra3d"
+)
+
+// Where things are in the scene: tile i, j runs from x = i*tileSize-8 and z = j*tileSize-8, the tiles' surface is at y = boardHeight, and each piece of a stack is pieceHeight above the last.
+const (
+	tileSize    = 2.0
+	boardHeight = 0.1
+	pieceHeight = 0.4
+)
+
+// A Selection is what the player has picked up, ready to be put down on another tile.
+type Selection struct {
+	Active bool
+	// FromReserve is set when placing a piece from the reserve, rather than moving the stack on X, Y.
+	FromReserve bool
+	X, Y        int
+	// Pieces is how many pieces to lift off the top of the stack.
+	Pieces int
+}
+
+// tileCentre returns where in the scene the middle of a tile is.
+func tileCentre(x int, y int) (worldX float64, worldZ float64) {
+	return (float64(x)+0.5)*tileSize - 8, (float64(y)+0.5)*tileSize - 8
+}
+
+// Pick returns the tile under a point on the screen, checking the tops of stacks before the board so that tall stacks can be clicked on.
+func (vis *Visualizer) Pick(screenX int, screenY int) (x int, y int, ok bool) {
+	camera := vis.Camera()
+	from := camera.WorldPosition()
+	direction := camera.ScreenToWorld(screenX, screenY, 1).Sub(from)
+	// Looking up from under the board can't hit anything.
+	if direction.Y >= 0 {
+		return 0, 0, false
+	}
+	for k := len(game.Tile{}.Pieces); k >= 0; k-- {
+		height := boardHeight + float64(k)*pieceHeight
+		hit := from.Add(direction.Scale((height - from.Y) / direction.Y))
+		x = int(math.Floor((hit.X + 8) / tileSize))
+		y = int(math.Floor((hit.Z + 8) / tileSize))
+		if x < 0 || x > 7 || y < 0 || y > 7 {
+			continue
+		}
+		// On the board itself anything goes, higher up only a stack that tall.
+		if k == 0 || vis.Board.Tiles[x][y].Height() >= k {
+			return x, y, true
+		}
+	}
+	return 0, 0, false
+}
+
+// handleInput lets the player to move pick up pieces with the mouse and put them down somewhere else.
+func (vis *Visualizer) handleInput() {
+	if vis.Over || !vis.Human[vis.Turn] {
+		return
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		vis.Selection = Selection{}
+		vis.Message = ""
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		if *vis.Board.GetReserves(vis.Turn) > 0 {
+			vis.Selection = Selection{Active: true, FromReserve: true, Pieces: 1}
+			vis.Message = "Click a tile to place a reserve piece on"
+		} else {
+			vis.Message = game.ErrNoReserves.Error()
+		}
+	}
+	if vis.Selection.Active && !vis.Selection.FromReserve {
+		vis.choosePieces()
+	}
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	x, y, ok := vis.Pick(ebiten.CursorPosition())
+	if !ok {
+		return
+	}
+	switch {
+	case !vis.Selection.Active:
+		vis.selectStack(x, y)
+	case !vis.Selection.FromReserve && x == vis.Selection.X && y == vis.Selection.Y:
+		// Clicking the stack again puts it back down.
+		vis.Selection = Selection{}
+		vis.Message = ""
+	case vis.Selection.FromReserve:
+		vis.Play(game.Move{X: x, Y: y, Pieces: 1, FromReserve: true})
+	default:
+		vis.Play(game.StackMove(vis.Selection.X, vis.Selection.Y, vis.Selection.Pieces, x, y))
+	}
+}
+
+// selectStack picks up the whole stack on a tile, if the player to move can move it.
+func (vis *Visualizer) selectStack(x int, y int) {
+	tile := &vis.Board.Tiles[x][y]
+	height := tile.Height()
+	if height == 0 {
+		vis.Message = game.ErrNoPieceToMove.Error()
+		return
+	}
+	for _, piece := range tile.Pieces {
+		if piece.Exists && piece.Color != vis.Turn {
+			vis.Message = game.ErrWrongColor.Error()
+			return
+		}
+	}
+	vis.Selection = Selection{Active: true, X: x, Y: y, Pieces: height}
+	vis.Message = fmt.Sprintf("Lifting %d, press 1-5 or scroll to change, then click where to put them", height)
+}
+
+// choosePieces changes how many pieces are lifted with the number keys or the mouse wheel.
+func (vis *Visualizer) choosePieces() {
+	pieces := vis.Selection.Pieces
+	for i, key := range []ebiten.Key{ebiten.Key1, ebiten.Key2, ebiten.Key3, ebiten.Key4, ebiten.Key5} {
+		if inpututil.IsKeyJustPressed(key) {
+			pieces = i + 1
+		}
+	}
+	if _, dy := ebiten.Wheel(); dy > 0 {
+		pieces++
+	} else if dy < 0 {
+		pieces--
+	}
+	height := vis.Board.Tiles[vis.Selection.X][vis.Selection.Y].Height()
+	if pieces < 1 {
+		pieces = 1
+	}
+	if pieces > height {
+		pieces = height
+	}
+	if pieces != vis.Selection.Pieces {
+		vis.Selection.Pieces = pieces
+		vis.Message = fmt.Sprintf("Lifting %d", pieces)
+	}
+}
+
+// Play plays a move for the player to move, checking it with the game's rules. An illegal move leaves the board as it was, and says why.
+func (vis *Visualizer) Play(move game.Move) (err error) {
+	after := *vis.Board
+	if err = after.Apply(move, vis.Turn); err != nil {
+		vis.Message = explain(err, move)
+		return err
+	}
+	if vis.AnimationSpeed > 0 {
+		if a, ok := newAnimation(*vis.Board, after, move, vis.Turn); ok {
+			vis.animations = append(vis.animations, a)
+		}
+	}
+	*vis.Board = after
+	vis.History = append(vis.History, move)
+	vis.Selection = Selection{}
+	vis.Message = fmt.Sprintf("%v played %v", vis.Turn, move)
+	vis.Turn = vis.Turn.Opponent()
+	if !vis.Board.HasLegalMove(vis.Turn) {
+		vis.Over = true
+		vis.Message = fmt.Sprintf("%v has no legal moves, %v wins!", vis.Turn, vis.Turn.Opponent())
+	}
+	if vis.OnMove != nil {
+		vis.OnMove(move)
+	}
+	return nil
+}
+
+// explain adds what the player can do about an illegal move to the game's error.
+func explain(err error, move game.Move) string {
+	switch {
+	case errors.Is(err, game.ErrWrongDirectionAmount):
+		return fmt.Sprintf("%v: %d pieces take %d steps, so they can't end on that tile", err, move.Pieces, move.Pieces)
+	case errors.Is(err, game.ErrTileOutOfBounds), errors.Is(err, game.ErrTileDestinationUnusable):
+		return fmt.Sprintf("%v: pick a tile on the board", err)
+	}
+	return err.Error()
+}
+
+// drawStatus writes whose turn it is, unless the HUD is showing it, and the last message in the corner of the screen.
+func (vis *Visualizer) drawStatus(screen *ebiten.Image) {
+	y := 16.0
+	if !vis.DrawHUD {
+		status := fmt.Sprintf("%v to move. Reserves: red %d, green %d (R to place one)", vis.Turn, vis.Board.ReservesR, vis.Board.ReservesG)
+		if vis.Over {
+			status = "Game over"
+		}
+		vis.drawText(screen, status, 16, y, tetra3d.NewColor(1, 1, 1, 1))
+		y += lineHeight
+	}
+	if vis.Message != "" {
+		vis.drawText(screen, vis.Message, 16, y, tetra3d.NewColor(1, 0.9, 0.4, 1))
+		y += lineHeight
+	}
+	if vis.highlighting {
+		vis.drawText(screen, highlightLegend, 16, y, tetra3d.NewColor(0.8, 0.8, 0.8, 1))
+	}
+}
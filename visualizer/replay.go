@@ -0,0 +1,176 @@
+package visualizer
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/solarlune/tetra3d"
+)
+
+// A Replay steps through a recorded game instead of playing one.
+type Replay struct {
+	Record game.Record
+	// Boards is the board before every move, and after the last.
+	Boards []game.Board
+	// Ply is how many moves into the game the board is.
+	Ply int
+	// Playing steps forward every Interval, once the last move has finished being animated.
+	Playing  bool
+	Interval time.Duration
+	waited   time.Duration
+}
+
+// The timeline runs along the bottom of the screen, measured like text.
+const (
+	timelineMargin = 16
+	timelineHeight = 12
+	// timelineGrab is how far above and below the timeline a click still seeks.
+	timelineGrab = 8
+)
+
+// OpenReplay shows a recorded game from its start, in place of any game being played.
+func (vis *Visualizer) OpenReplay(record game.Record) (err error) {
+	boards, err := record.Positions()
+	if err != nil {
+		return err
+	}
+	vis.Replay = &Replay{Record: record, Boards: boards, Interval: time.Second}
+	vis.Human = [2]bool{false, false}
+	vis.Selection = Selection{}
+	vis.Message = ""
+	vis.Evaluation = Evaluation{}
+	vis.Seek(0)
+	return nil
+}
+
+// Seek shows the replay ply moves in. Stepping forward one move is animated, anything else jumps straight there.
+func (vis *Visualizer) Seek(ply int) {
+	r := vis.Replay
+	if ply < 0 {
+		ply = 0
+	}
+	if ply > len(r.Record.Moves) {
+		ply = len(r.Record.Moves)
+	}
+	if ply == r.Ply+1 && vis.AnimationSpeed > 0 {
+		if a, ok := newAnimation(r.Boards[r.Ply], r.Boards[ply], r.Record.Moves[r.Ply], r.Record.TurnAt(r.Ply)); ok {
+			vis.animations = append(vis.animations, a)
+		}
+	} else {
+		// The models may be part way through a move, so set them all again.
+		vis.animations = nil
+		vis.synced = false
+	}
+	r.Ply = ply
+	r.waited = 0
+	*vis.Board = r.Boards[ply]
+	vis.Turn = r.Record.TurnAt(ply)
+	// Capped, so nothing appended to the history can change the record.
+	vis.History = r.Record.Moves[:ply:ply]
+	vis.Over = ply == len(r.Record.Moves) && r.Record.Result != game.UNDECIDED
+}
+
+// handleReplay steps through the replay.
+//
+// Comma and full stop step back and forward, and with shift go to the start and end. Space plays and pauses, and [ and ] slow it down and speed it up. Clicking or dragging on the timeline jumps to that move.
+func (vis *Visualizer) handleReplay() {
+	r := vis.Replay
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyComma) && shift:
+		vis.Seek(0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyPeriod) && shift:
+		vis.Seek(len(r.Record.Moves))
+	case inpututil.IsKeyJustPressed(ebiten.KeyComma):
+		vis.Seek(r.Ply - 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyPeriod):
+		vis.Seek(r.Ply + 1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		r.Playing = !r.Playing
+		// Playing from the end starts again.
+		if r.Playing && r.Ply == len(r.Record.Moves) {
+			vis.Seek(0)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeftBracket) {
+		r.Interval *= 2
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRightBracket) && r.Interval > 50*time.Millisecond {
+		r.Interval /= 2
+	}
+
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		if ply, ok := vis.timelinePly(ebiten.CursorPosition()); ok && ply != r.Ply {
+			vis.Seek(ply)
+		}
+	}
+
+	if !r.Playing || len(vis.animations) > 0 {
+		return
+	}
+	if r.Ply == len(r.Record.Moves) {
+		r.Playing = false
+		return
+	}
+	r.waited += time.Second / time.Duration(ebiten.TPS())
+	if r.waited >= r.Interval {
+		vis.Seek(r.Ply + 1)
+	}
+}
+
+// timeline returns where the timeline is, measured like text.
+func (vis *Visualizer) timeline() (x float64, y float64, width float64) {
+	scale := float64(vis.Height) / textHeight
+	return timelineMargin, textHeight - timelineMargin - timelineHeight, float64(vis.Width)/scale - 2*timelineMargin
+}
+
+// timelinePly returns the move of the replay at a point on the screen, if it is on the timeline.
+func (vis *Visualizer) timelinePly(screenX int, screenY int) (ply int, ok bool) {
+	scale := float64(vis.Height) / textHeight
+	x, y, width := vis.timeline()
+	px, py := float64(screenX)/scale, float64(screenY)/scale
+	if px < x || px > x+width || py < y-timelineGrab || py > y+timelineHeight+timelineGrab {
+		return 0, false
+	}
+	return int(math.Round((px - x) / width * float64(len(vis.Replay.Record.Moves)))), true
+}
+
+// drawTimeline draws the timeline, filled up to the move being shown with a mark for every move, and what is happening above it.
+func (vis *Visualizer) drawTimeline(screen *ebiten.Image) {
+	r := vis.Replay
+	scale := float64(vis.Height) / textHeight
+	x, y, width := vis.timeline()
+	plies := len(r.Record.Moves)
+	rect := func(x, y, w, h float64, c color.Color) {
+		vector.DrawFilledRect(screen, float32(x*scale), float32(y*scale), float32(w*scale), float32(h*scale), c, false)
+	}
+	rect(x, y, width, timelineHeight, color.RGBA{A: 160})
+	done := width
+	if plies > 0 {
+		done = width * float64(r.Ply) / float64(plies)
+	}
+	rect(x, y, done, timelineHeight, color.RGBA{R: 90, G: 140, B: 220, A: 220})
+	// Marks for every move, if there is room for them.
+	if plies > 0 && width/float64(plies) >= 3 {
+		for i := 1; i < plies; i++ {
+			rect(x+width*float64(i)/float64(plies), y, 0.5, timelineHeight, color.RGBA{R: 200, G: 200, B: 200, A: 120})
+		}
+	}
+
+	state := "paused"
+	if r.Playing {
+		state = fmt.Sprintf("playing, a move every %v", r.Interval)
+	}
+	label := fmt.Sprintf("Move %d of %d, %s (space to play, , and . to step, [ and ] for speed)", r.Ply, plies, state)
+	if r.Record.Red != "" || r.Record.Green != "" {
+		label = fmt.Sprintf("%s v %s. %s", r.Record.Red, r.Record.Green, label)
+	}
+	vis.drawText(screen, label, x, y-lineHeight-14, tetra3d.NewColor(1, 1, 1, 1))
+}
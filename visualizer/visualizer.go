@@ -1,6 +1,7 @@
 package visualizer
 
 import (
+	"context"
 	"errors"
 
 	_ "embed"
@@ -13,13 +14,59 @@ import (
 )
 
 type Visualizer struct {
+	// Width and Height are the size the scene is drawn at, which follows the size of the window.
 	Width, Height  int
 	Library        *tetra3d.Library
 	Scene          *tetra3d.Scene
 	DrawDebugStats bool
+	DrawHUD        bool
 	Board          *game.Board
 	RedPieces      [8][8][5]*tetra3d.Model
 	GreenPieces    [8][8][5]*tetra3d.Model
+	// Highlights lie on each tile, to show where the selected pieces can go.
+	Highlights   [8][8]*tetra3d.Model
+	highlighting bool
+	// Flying are the pieces moved about by animations, and ReservePiles show each player's reserves beside the board, by colour.
+	Flying       [2][10]*tetra3d.Model
+	ReservePiles [2][maxReservesShown]*tetra3d.Model
+	// AnimationSpeed is how fast moves are animated, 1 being normal speed. 0 or less shows moves straight away, such as for fast replays.
+	AnimationSpeed float64
+	animations     []*animation
+	// shown is the board the models were last set to match, if synced is set.
+	shown  game.Board
+	synced bool
+	// Orbit is where the camera is. sceneOrbit is where the scene put it, to go back to.
+	Orbit      Orbit
+	sceneOrbit Orbit
+	// cursor is where the mouse was last frame, for dragging the camera.
+	cursor [2]int
+	// Turn is whose move it is.
+	Turn game.Color
+	// Human is which colours are played with the mouse, by colour. Moves for the others are sent to Moves.
+	Human [2]bool
+	// Moves are played on the board as they arrive, such as from an agent.
+	Moves chan game.Move
+	// History is every move played since the visualizer started.
+	History []game.Move
+	// Evaluations from agents searching are shown on the HUD as they arrive, the latest in Evaluation.
+	Evaluations chan Evaluation
+	Evaluation  Evaluation
+	// Analyst, if set, works out the heatmap shown over the board when Heatmap isn't off.
+	Analyst        Analyst
+	Heatmap        HeatmapMode
+	Heat           [8][8]*tetra3d.Model
+	analyses       chan analysed
+	analysis       *analysed
+	requested      *position
+	cancelAnalysis context.CancelFunc
+	// Replay, if set, is a recorded game being stepped through instead of played.
+	Replay *Replay
+	// OnMove, if set, is called after every move is played.
+	OnMove    func(move game.Move)
+	Selection Selection
+	// Message is shown under the status, such as why a move wasn't allowed.
+	Message string
+	Over    bool
 }
 
 //go:embed startingScene.gltf
@@ -30,6 +77,15 @@ func NewVisualizer(board *game.Board) *Visualizer {
 		Width:  3840,
 		Height: 2160,
 		Board:  board,
+		Turn:   game.RED,
+		Human:  [2]bool{true, true},
+		Moves:  make(chan game.Move, 1),
+
+		DrawHUD:     true,
+		Evaluations: make(chan Evaluation, 16),
+		analyses:    make(chan analysed, 8),
+
+		AnimationSpeed: 1,
 	}
 	vis.Init()
 	return vis
@@ -48,6 +104,15 @@ func (vis *Visualizer) Init() {
 		vis.Library = library
 	}
 	vis.Scene = vis.Library.ExportedScene.Clone()
+	// The models are all new, so nothing on them matches the board yet.
+	vis.synced = false
+
+	// Keep the camera where it was, unless there is nowhere yet.
+	vis.sceneOrbit = orbitFrom(vis.Camera())
+	if vis.Orbit.Distance == 0 {
+		vis.Orbit = vis.sceneOrbit
+	}
+	vis.placeCamera()
 
 	// Get the green piece
 	greenPiece := vis.Scene.Root.Get("GreenPiece").(*tetra3d.Model)
@@ -58,7 +123,7 @@ func (vis *Visualizer) Init() {
 				piece := greenPiece.Clone().(*tetra3d.Model)
 				vis.GreenPieces[i][j][k] = piece
 				vis.Scene.Root.AddChildren(piece)
-				piece.SetWorldPosition((float64(i)*2)-8, (float64(k)*0.4)+0.2, (float64(j)*2)-6)
+				piece.SetWorldPositionVec(slotPosition(i, j, k))
 				piece.SetVisible(false, true)
 			}
 		}
@@ -72,34 +137,54 @@ func (vis *Visualizer) Init() {
 				piece := redPiece.Clone().(*tetra3d.Model)
 				vis.RedPieces[i][j][k] = piece
 				vis.Scene.Root.AddChildren(piece)
-				piece.SetWorldPosition((float64(i)*2)-8, (float64(k)*0.4)+0.2, (float64(j)*2)-6)
+				piece.SetWorldPositionVec(slotPosition(i, j, k))
 				piece.SetVisible(false, true)
 			}
 		}
 	}
+	vis.newSpareModels([2]*tetra3d.Model{redPiece, greenPiece})
+	vis.animations = nil
 
 	// Delete the original RedPiece and GreenPiece
 	vis.Scene.Root.RemoveChildren(greenPiece)
 	vis.Scene.Root.RemoveChildren(redPiece)
+
+	vis.newHighlights()
+	vis.newHeat()
+}
+
+// Sync sets every piece model to match the board, showing exactly the pieces that exist in their colour, and the reserves.
+func (vis *Visualizer) Sync() {
+	vis.show(vis.Board)
+	vis.shown = *vis.Board
+	vis.synced = true
 }
 
 func (vis *Visualizer) Update() (err error) {
-	// Update the scene based on the current Board
-	for i := 0; i < 8; i++ {
-		for j := 0; j < 8; j++ {
-			tile := vis.Board.Tiles[i][j]
-			for k := 0; k < len(tile.Pieces); k++ {
-				if !tile.Pieces[k].Exists {
-					continue
-				}
-				if tile.Pieces[k].Color == game.RED {
-					vis.RedPieces[i][j][k].SetVisible(true, true)
-				} else {
-					vis.GreenPieces[i][j][k].SetVisible(true, true)
-				}
-			}
+	// Update the scene whenever the board has changed since it was last shown, once any moves have finished being animated
+	animating := vis.animate()
+	if !animating && (!vis.synced || *vis.Board != vis.shown) {
+		vis.Sync()
+	}
+
+	// Moves sent in wait for the last one to finish moving, so fast agents don't get ahead of what is shown.
+	if !animating {
+		select {
+		case move := <-vis.Moves:
+			vis.Play(move)
+		default:
 		}
 	}
+	for len(vis.Evaluations) > 0 {
+		vis.Evaluation = <-vis.Evaluations
+	}
+	vis.handleCamera()
+	if vis.Replay != nil {
+		vis.handleReplay()
+	}
+	vis.handleInput()
+	vis.updateHighlights()
+	vis.updateHeatmap()
 
 	// Quit
 	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
@@ -111,6 +196,16 @@ func (vis *Visualizer) Update() (err error) {
 		ebiten.SetFullscreen(!ebiten.IsFullscreen())
 	}
 
+	// HUD
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		vis.DrawHUD = !vis.DrawHUD
+	}
+
+	// Heatmap, from off through each mode
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		vis.Heatmap = (vis.Heatmap + 1) % HEATMAP_MODES
+	}
+
 	// Stats for nerds
 	if inpututil.IsKeyJustPressed(ebiten.KeyF8) {
 		vis.DrawDebugStats = !vis.DrawDebugStats
@@ -119,16 +214,29 @@ func (vis *Visualizer) Update() (err error) {
 	return err
 }
 
+func (vis *Visualizer) Camera() *tetra3d.Camera {
+	return vis.Scene.Root.Get("Camera").(*tetra3d.Camera)
+}
+
 func (vis *Visualizer) Draw(screen *ebiten.Image) {
 
 	screen.Fill(vis.Scene.World.ClearColor.ToRGBA64())
 
-	camera := vis.Scene.Root.Get("Camera").(*tetra3d.Camera)
+	camera := vis.Camera()
 
 	camera.Clear()
 	camera.RenderNodes(vis.Scene, vis.Scene.Root)
 	screen.DrawImage(camera.ColorTexture(), nil)
 
+	vis.drawHeatmap(screen)
+	vis.drawStatus(screen)
+	if vis.DrawHUD {
+		vis.drawHUD(screen)
+	}
+	if vis.Replay != nil {
+		vis.drawTimeline(screen)
+	}
+
 	if vis.DrawDebugStats {
 		camera.DrawDebugRenderInfo(screen, 1, colors.White())
 	}
@@ -136,7 +244,14 @@ func (vis *Visualizer) Draw(screen *ebiten.Image) {
 }
 
 func (vis *Visualizer) Layout(w, h int) (int, int) {
-	// This is a fixed aspect ratio; we can change this to, say, extend for wider displays by using the provided w argument and
-	// calculating the height from the aspect ratio, then calling Camera.Resize() on any / all cameras with the new width and height.
+	// Draw at the window's size in real pixels, so nothing is stretched on wide screens or blurry on high DPI ones.
+	scale := ebiten.DeviceScaleFactor()
+	w, h = int(float64(w)*scale), int(float64(h)*scale)
+	// A minimised window has no size, so keep the last one.
+	if w > 0 && h > 0 {
+		vis.Width, vis.Height = w, h
+	}
+	// Resizing to the same size does nothing.
+	vis.Camera().Resize(vis.Width, vis.Height)
 	return vis.Width, vis.Height
 }
@@ -0,0 +1,122 @@
+//go:build visualizer
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+	"github.com/headblockhead/focus-ai/search"
+	"github.com/headblockhead/focus-ai/visualizer"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// openWindow runs the visualizer until it is closed. Players that are nil are played with the mouse, the others are agents.
+func openWindow(board game.Board, turn game.Color, red agent.Agent, green agent.Agent, options windowOptions) error {
+	vis, err := newVisualizer(&board, options)
+	if err != nil {
+		return err
+	}
+	vis.Turn = turn
+	vis.Human = [2]bool{red == nil, green == nil}
+
+	// Agents think in the background, and hand their moves to the visualizer to play.
+	players := [2]agent.Agent{red, green}
+	think := func(board game.Board, turn game.Color) {
+		player := players[turn]
+		if player == nil || !board.HasLegalMove(turn) {
+			return
+		}
+		// Let the HUD show how the agent sees the position as it thinks, without holding up its search.
+		ctx := agent.WithInfo(context.Background(), func(info agent.Info) {
+			select {
+			case vis.Evaluations <- visualizer.Evaluation{Agent: player.Name(), Turn: turn, Depth: info.Depth, Score: info.Score}:
+			default:
+			}
+		})
+		go func() {
+			move, err := player.SelectMove(ctx, board, turn)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", player.Name(), err)
+				return
+			}
+			vis.Moves <- move
+		}()
+	}
+	vis.OnMove = func(move game.Move) {
+		think(*vis.Board, vis.Turn)
+	}
+	think(board, turn)
+	return runWindow(vis)
+}
+
+// openReplay runs the visualizer on a recorded game until it is closed, playing through it every interval if play is set.
+func openReplay(record game.Record, interval time.Duration, play bool, options windowOptions) error {
+	board := record.Start
+	vis, err := newVisualizer(&board, options)
+	if err != nil {
+		return err
+	}
+	if err = vis.OpenReplay(record); err != nil {
+		return err
+	}
+	vis.Replay.Interval = interval
+	vis.Replay.Playing = play
+	return runWindow(vis)
+}
+
+func newVisualizer(board *game.Board, options windowOptions) (vis *visualizer.Visualizer, err error) {
+	vis = visualizer.NewVisualizer(board)
+	vis.AnimationSpeed = options.speed
+	if options.heatmap != "" {
+		vis.Analyst, err = newAnalyst(options.heatmap)
+	}
+	return vis, err
+}
+
+// mctsAnalyst shows what an MCTS search makes of positions on the heatmap, with the share of the search each move gets as the policy, and the win rate as the value.
+type mctsAnalyst struct {
+	mcts *search.MCTS
+}
+
+func newAnalyst(spec string) (visualizer.Analyst, error) {
+	a, closeAgent, err := newAgent(spec)
+	if err != nil {
+		return nil, err
+	}
+	// Only agents searching in this process can be looked into, so there is never an engine to keep running.
+	defer closeAgent()
+	if named, ok := a.(*agent.Named); ok {
+		a = named.Agent
+	}
+	m, ok := a.(*search.MCTS)
+	if !ok {
+		return nil, fmt.Errorf("The heatmap needs an agent with a policy, such as mcts, not %q", spec)
+	}
+	return mctsAnalyst{mcts: m}, nil
+}
+
+func (a mctsAnalyst) Analyse(ctx context.Context, board game.Board, turn game.Color) (analysis visualizer.Analysis, err error) {
+	result, err := a.mcts.Search(ctx, board, turn)
+	if err != nil {
+		return analysis, err
+	}
+	return visualizer.Analysis{Moves: board.LegalMoves(turn), Policy: result.Policy, Value: 2*result.Score - 1}, nil
+}
+
+func runWindow(vis *visualizer.Visualizer) error {
+	ebiten.SetWindowTitle("Focus AI Visualizer")
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetWindowSize(1280, 720)
+
+	// An ungraceful quit
+	if err := ebiten.RunGame(vis); err != nil && err.Error() != "quit" {
+		return err
+	}
+	return nil
+}
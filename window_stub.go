@@ -0,0 +1,24 @@
+//go:build !visualizer
+
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/headblockhead/focus-ai/agent"
+	"github.com/headblockhead/focus-ai/game"
+)
+
+// The visualizer needs a display and a GPU, so it is left out unless asked for, letting everything else run on servers.
+var (
+	errNoVisualizer = errors.New("This focus-ai was built without the visualizer, build it with -tags visualizer to use it")
+)
+
+func openWindow(board game.Board, turn game.Color, red agent.Agent, green agent.Agent, options windowOptions) error {
+	return errNoVisualizer
+}
+
+func openReplay(record game.Record, interval time.Duration, play bool, options windowOptions) error {
+	return errNoVisualizer
+}